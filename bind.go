@@ -0,0 +1,488 @@
+package gorouter
+
+import (
+	"encoding"
+	"errors"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+var (
+	BindTargetError = errors.New("bind target must be a pointer to a struct")
+	BindRuleError   = errors.New("invalid validation rule")
+)
+
+// bindSources are the struct tags of Bind, the first found tag of the field is its source.
+var bindSources = []string{"path", "query", "form", "header", "cookie"}
+
+// FieldError is the error of the single field: the value can't be converted or it breaks the validation rule.
+type FieldError struct {
+	Field   string `json:"field"`            // name of the param, header, cookie or the struct field
+	Source  string `json:"source,omitempty"` // path, query, form, header, cookie or empty for validated only fields
+	Rule    string `json:"rule"`             // failed validation rule or "type" for conversion errors
+	Message string `json:"message"`
+}
+
+func (e FieldError) Error() string {
+	return e.Field + ": " + e.Message
+}
+
+// BindError collects the errors of all fields.
+type BindError struct {
+	Fields []FieldError
+}
+
+func (e *BindError) Error() string {
+	list := make([]string, len(e.Fields))
+	for i := range e.Fields {
+		list[i] = e.Fields[i].Error()
+	}
+
+	return strings.Join(list, "; ")
+}
+
+func (e *BindError) add(field *bindField, rule, message string) {
+	e.Fields = append(e.Fields, FieldError{Field: field.name, Source: field.source, Rule: rule, Message: message})
+}
+
+type bindRule struct {
+	name  string
+	param string
+	limit float64 // parsed param of min, max and len
+}
+
+type bindField struct {
+	index  []int
+	name   string
+	source string // empty if the field is validated only
+	rules  []bindRule
+}
+
+// bindCache keeps []*bindField or the error of the struct type.
+var bindCache sync.Map
+
+var (
+	timeType          = reflect.TypeOf(time.Time{})
+	durationType      = reflect.TypeOf(time.Duration(0))
+	textUnmarshalType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+)
+
+// Bind fills the fields of the struct from the request per their tags: `path:"id"`, `query:"page"`, `form:"name"`,
+// `header:"X-Request-Id"` and `cookie:"sid"`, then it checks `validate:"required,min=1,max=100,email,oneof=a b"` rules.
+// Strings, bools, numbers, time.Duration, time.Time (RFC 3339 or date), encoding.TextUnmarshaler,
+// slices (multi values) and pointers of them are supported. Fields without values are not changed.
+// Errors of all fields are returned at once as HTTPError 400 with the *BindError cause and []FieldError details.
+func (ctx *Context) Bind(v any) error {
+	fields, target, err := bindTarget(v)
+	if err != nil {
+		return err
+	}
+
+	bindErr := &BindError{}
+	failed := map[*bindField]bool{}
+	for _, field := range fields {
+		if field.source == "" {
+			continue
+		}
+
+		values := ctx.bindValues(field.source, field.name)
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(target.FieldByIndex(field.index), values); err != nil {
+			bindErr.add(field, "type", err.Error())
+			failed[field] = true
+		}
+	}
+
+	for _, field := range fields {
+		if !failed[field] {
+			validateField(bindErr, field, target.FieldByIndex(field.index))
+		}
+	}
+
+	if len(bindErr.Fields) > 0 {
+		return BadRequest("invalid request").WithDetails(bindErr.Fields).Wrap(bindErr)
+	}
+
+	return nil
+}
+
+// Validate checks `validate` rules of the struct fields, for example, after BindJSON. The error is *BindError.
+func Validate(v any) error {
+	fields, target, err := bindTarget(v)
+	if err != nil {
+		return err
+	}
+
+	bindErr := &BindError{}
+	for _, field := range fields {
+		validateField(bindErr, field, target.FieldByIndex(field.index))
+	}
+
+	if len(bindErr.Fields) > 0 {
+		return bindErr
+	}
+
+	return nil
+}
+
+func bindTarget(v any) ([]*bindField, reflect.Value, error) {
+	target := reflect.ValueOf(v)
+	if target.Kind() != reflect.Pointer || target.IsNil() || target.Elem().Kind() != reflect.Struct {
+		return nil, target, fmt.Errorf("%w: %T", BindTargetError, v)
+	}
+
+	target = target.Elem()
+	if cached, ok := bindCache.Load(target.Type()); ok {
+		if err, isErr := cached.(error); isErr {
+			return nil, target, err
+		}
+		return cached.([]*bindField), target, nil
+	}
+
+	fields, err := parseBindFields(target.Type(), nil)
+	if err != nil {
+		bindCache.Store(target.Type(), err)
+		return nil, target, err
+	}
+
+	bindCache.Store(target.Type(), fields)
+	return fields, target, nil
+}
+
+// parseBindFields collects the tagged fields of the struct, embedded structs without tags are parsed recursively.
+func parseBindFields(t reflect.Type, index []int) ([]*bindField, error) {
+	var out []*bindField
+
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		fieldIndex := append(append([]int{}, index...), i)
+
+		field := &bindField{index: fieldIndex}
+		for _, source := range bindSources {
+			if name, ok := sf.Tag.Lookup(source); ok && name != "" && name != "-" {
+				field.source, field.name = source, name
+				break
+			}
+		}
+
+		validate := sf.Tag.Get("validate")
+		if field.source == "" && validate == "" {
+			if sf.Anonymous && sf.Type.Kind() == reflect.Struct {
+				list, err := parseBindFields(sf.Type, fieldIndex)
+				if err != nil {
+					return nil, err
+				}
+				out = append(out, list...)
+			}
+			continue
+		}
+
+		if !sf.IsExported() {
+			return nil, fmt.Errorf("%w: field '%s' is not exported", BindRuleError, sf.Name)
+		}
+
+		if field.name == "" {
+			field.name = sf.Name
+			if name, _, _ := strings.Cut(sf.Tag.Get("json"), ","); name != "" && name != "-" {
+				field.name = name
+			}
+		}
+
+		rules, err := parseBindRules(validate)
+		if err != nil {
+			return nil, fmt.Errorf("%w, field '%s'", err, sf.Name)
+		}
+		field.rules = rules
+
+		out = append(out, field)
+	}
+
+	return out, nil
+}
+
+func parseBindRules(validate string) ([]bindRule, error) {
+	var out []bindRule
+
+	for _, s := range strings.Split(validate, ",") {
+		if s = strings.TrimSpace(s); s == "" {
+			continue
+		}
+
+		name, param, _ := strings.Cut(s, "=")
+		rule := bindRule{name: name, param: param}
+
+		switch name {
+		case "required", "omitempty", "email", "uuid":
+		case "min", "max", "len":
+			limit, err := strconv.ParseFloat(param, 64)
+			if err != nil {
+				return nil, fmt.Errorf("%w: '%s' needs the number", BindRuleError, s)
+			}
+			rule.limit = limit
+		case "oneof":
+			if strings.TrimSpace(param) == "" {
+				return nil, fmt.Errorf("%w: '%s' needs the list of values", BindRuleError, s)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unknown rule '%s'", BindRuleError, name)
+		}
+
+		out = append(out, rule)
+	}
+
+	return out, nil
+}
+
+// bindValues returns the raw values of the param from the source.
+func (ctx *Context) bindValues(source, name string) []string {
+	var raw [][]byte
+
+	switch source {
+	case "path":
+		if ctx.urlIDs != nil {
+			raw = ctx.urlIDs.PeekMulti(name)
+		}
+	case "query":
+		raw = ctx.fastCtx.QueryArgs().PeekMulti(name)
+	case "form":
+		raw = ctx.fastCtx.PostArgs().PeekMulti(name)
+		if len(raw) == 0 {
+			if form, err := ctx.fastCtx.MultipartForm(); err == nil {
+				return form.Value[name]
+			}
+		}
+	case "header":
+		raw = ctx.fastCtx.Request.Header.PeekAll(name)
+	case "cookie":
+		value := ctx.fastCtx.Request.Header.Cookie(name)
+		if len(value) == 0 {
+			return nil
+		}
+
+		// SetCookie escapes values
+		if s, err := url.QueryUnescape(string(value)); err == nil {
+			return []string{s}
+		}
+		raw = [][]byte{value}
+	}
+
+	out := make([]string, len(raw))
+	for i := range raw {
+		out[i] = string(raw[i])
+	}
+
+	return out
+}
+
+func setField(v reflect.Value, values []string) error {
+	if v.Kind() == reflect.Slice && v.Type().Elem().Kind() != reflect.Uint8 {
+		list := reflect.MakeSlice(v.Type(), len(values), len(values))
+		for i := range values {
+			if err := setValue(list.Index(i), values[i]); err != nil {
+				return err
+			}
+		}
+
+		v.Set(list)
+		return nil
+	}
+
+	return setValue(v, values[0])
+}
+
+func setValue(v reflect.Value, s string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), s); err != nil {
+			return err
+		}
+
+		v.Set(ptr)
+		return nil
+	}
+
+	switch v.Type() {
+	case timeType:
+		for _, layout := range []string{time.RFC3339Nano, time.DateOnly} {
+			if t, err := time.Parse(layout, s); err == nil {
+				v.Set(reflect.ValueOf(t))
+				return nil
+			}
+		}
+		return typeError(s, "time")
+	case durationType:
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return typeError(s, "duration")
+		}
+		v.SetInt(int64(d))
+		return nil
+	}
+
+	if v.Addr().Type().Implements(textUnmarshalType) {
+		if err := v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(s)); err != nil {
+			return typeError(s, v.Type().Name())
+		}
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return typeError(s, "bool")
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return typeError(s, "int")
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return typeError(s, "uint")
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return typeError(s, "float")
+		}
+		v.SetFloat(f)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
+
+func typeError(value, expected string) error {
+	return fmt.Errorf("invalid value '%s', %s is expected", value, expected)
+}
+
+// validateField checks the rules of the field, only the first failed rule is reported.
+func validateField(bindErr *BindError, field *bindField, v reflect.Value) {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			for _, rule := range field.rules {
+				if rule.name == "required" {
+					bindErr.add(field, rule.name, "is required")
+				}
+			}
+			return
+		}
+		v = v.Elem()
+	}
+
+	for _, rule := range field.rules {
+		if message := checkRule(rule, v); message == "-" {
+			return
+		} else if message != "" {
+			bindErr.add(field, rule.name, message)
+			return
+		}
+	}
+}
+
+// checkRule returns the error message, empty string if the value is valid or "-" to skip other rules.
+func checkRule(rule bindRule, v reflect.Value) string {
+	switch rule.name {
+	case "omitempty":
+		if v.IsZero() {
+			return "-"
+		}
+	case "required":
+		if v.IsZero() {
+			return "is required"
+		}
+	case "min", "max", "len":
+		return checkLimit(rule, v)
+	case "email", "uuid", "oneof":
+		if v.Kind() == reflect.Slice || v.Kind() == reflect.Array {
+			for i := 0; i < v.Len(); i++ {
+				if message := checkString(rule, v.Index(i)); message != "" {
+					return message
+				}
+			}
+			return ""
+		}
+		return checkString(rule, v)
+	}
+
+	return ""
+}
+
+func checkLimit(rule bindRule, v reflect.Value) string {
+	var value float64
+	what := "value"
+
+	switch v.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value = float64(v.Int())
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		value = float64(v.Uint())
+	case reflect.Float32, reflect.Float64:
+		value = v.Float()
+	case reflect.String:
+		value, what = float64(utf8.RuneCountInString(v.String())), "length"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		value, what = float64(v.Len()), "number of items"
+	default:
+		return ""
+	}
+
+	switch {
+	case rule.name == "min" && value < rule.limit:
+		return what + " must be at least " + rule.param
+	case rule.name == "max" && value > rule.limit:
+		return what + " must be at most " + rule.param
+	case rule.name == "len" && value != rule.limit:
+		return what + " must be " + rule.param
+	}
+
+	return ""
+}
+
+func checkString(rule bindRule, v reflect.Value) string {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	s := fmt.Sprint(v.Interface())
+	switch rule.name {
+	case "email":
+		if addr, err := mail.ParseAddress(s); err != nil || addr.Address != s {
+			return "must be a valid email"
+		}
+	case "uuid":
+		if !isUUID(s) {
+			return "must be a valid UUID"
+		}
+	case "oneof":
+		for _, item := range strings.Fields(rule.param) {
+			if item == s {
+				return ""
+			}
+		}
+		return "must be one of: " + rule.param
+	}
+
+	return ""
+}
@@ -0,0 +1,130 @@
+package gorouter
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type bindPaging struct {
+	Page  int `query:"page" validate:"omitempty,min=1"`
+	Limit int `query:"limit" validate:"max=100"`
+}
+
+type bindRequest struct {
+	bindPaging
+
+	ID      uint64        `path:"id" validate:"required"`
+	Tags    []string      `query:"tag" validate:"max=3,oneof=a b c"`
+	Active  *bool         `query:"active"`
+	Since   time.Time     `query:"since"`
+	Wait    time.Duration `query:"wait"`
+	Email   string        `form:"email" validate:"required,email"`
+	Name    string        `form:"name" validate:"min=2,max=5"`
+	Request string        `header:"X-Request-Id" validate:"omitempty,uuid"`
+	Session string        `cookie:"sid" validate:"required"`
+	Note    string        `json:"note" validate:"max=3"`
+	skipped string
+}
+
+type BindHandler struct {
+	RunHandler
+
+	Note string // the initial value of the field which is not bound
+	Req  *bindRequest
+	Err  error
+}
+
+func (h *BindHandler) Run(ctx *Context) error {
+	h.Req = &bindRequest{Note: h.Note, skipped: "x"}
+	h.Err = ctx.Bind(h.Req)
+	return h.Err
+}
+
+func TestBind(t *testing.T) {
+	h := &BindHandler{}
+	g := New()
+	g.Router().Post("/items/:id", h)
+
+	serveTest(g, MethodPost, "/items/15?page=2&tag=a&tag=c&active=true&since=2024-05-01&wait=1m30s",
+		withForm("email=bob%40example.com&name=bob"),
+		withHeader("X-Request-Id", "7c9e6679-7425-40de-944b-e07fc1f90ae7"), withHeader("Cookie", "sid=a%2Fb"))
+
+	assert.Nil(t, h.Err)
+	assert.EqualValues(t, 15, h.Req.ID)
+	assert.EqualValues(t, 2, h.Req.Page)
+	assert.EqualValues(t, []string{"a", "c"}, h.Req.Tags)
+	assert.True(t, *h.Req.Active)
+	assert.EqualValues(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), h.Req.Since)
+	assert.EqualValues(t, 90*time.Second, h.Req.Wait)
+	assert.EqualValues(t, "bob@example.com", h.Req.Email)
+	assert.EqualValues(t, "bob", h.Req.Name)
+	assert.EqualValues(t, "7c9e6679-7425-40de-944b-e07fc1f90ae7", h.Req.Request)
+	assert.EqualValues(t, "a/b", h.Req.Session)
+	assert.EqualValues(t, "x", h.Req.skipped)
+
+	// all errors are returned at once, the untouched field keeps its value and is validated too
+	h.Note = "note"
+	fastCtx := serveTest(g, MethodPost, "/items/15?page=0&limit=500&tag=d&active=maybe&wait=1", withForm("email=bob&name=b"),
+		withHeader("X-Request-Id", "15"), withHeader("Accept", "application/json"))
+
+	bindErr := &BindError{}
+	assert.True(t, errors.As(h.Err, &bindErr))
+	assert.EqualValues(t, []FieldError{
+		{Field: "active", Source: "query", Rule: "type", Message: "invalid value 'maybe', bool is expected"},
+		{Field: "wait", Source: "query", Rule: "type", Message: "invalid value '1', duration is expected"},
+		{Field: "limit", Source: "query", Rule: "max", Message: "value must be at most 100"},
+		{Field: "tag", Source: "query", Rule: "oneof", Message: "must be one of: a b c"},
+		{Field: "email", Source: "form", Rule: "email", Message: "must be a valid email"},
+		{Field: "name", Source: "form", Rule: "min", Message: "length must be at least 2"},
+		{Field: "X-Request-Id", Source: "header", Rule: "uuid", Message: "must be a valid UUID"},
+		{Field: "sid", Source: "cookie", Rule: "required", Message: "is required"},
+		{Field: "note", Rule: "max", Message: "length must be at most 3"},
+	}, bindErr.Fields)
+
+	assert.EqualValues(t, fasthttp.StatusBadRequest, fastCtx.Response.StatusCode())
+	assert.Contains(t, string(fastCtx.Response.Body()), `{"field":"sid","source":"cookie","rule":"required","message":"is required"}`)
+}
+
+func TestBindTarget(t *testing.T) {
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Init(&fasthttp.Request{}, nil, nil)
+	ctx, err := NewContext(nil, fastCtx, nil)
+	assert.Nil(t, err)
+
+	assert.True(t, errors.Is(ctx.Bind(bindRequest{}), BindTargetError))
+	assert.True(t, errors.Is(ctx.Bind((*bindRequest)(nil)), BindTargetError))
+
+	type badRule struct {
+		ID int `query:"id" validate:"min=one"`
+	}
+	assert.True(t, errors.Is(ctx.Bind(&badRule{}), BindRuleError))
+
+	type unknownRule struct {
+		ID int `query:"id" validate:"positive"`
+	}
+	assert.True(t, errors.Is(Validate(&unknownRule{}), BindRuleError))
+}
+
+func TestValidate(t *testing.T) {
+	type item struct {
+		Name  string   `json:"name" validate:"required,len=3"`
+		Kind  *string  `json:"kind" validate:"required,oneof=box bag"`
+		Count *int     `validate:"min=1"`
+		Mails []string `json:"mails" validate:"min=1,email"`
+	}
+
+	kind, count := "bag", 0
+	assert.Nil(t, Validate(&item{Name: "abc", Kind: &kind, Mails: []string{"a@b.c"}}))
+
+	err := Validate(&item{Name: "ab", Count: &count, Mails: []string{"a@b.c", "x"}})
+	assert.EqualError(t, err, "name: length must be 3; kind: is required; Count: value must be at least 1; mails: must be a valid email")
+
+	kind = strings.ToUpper(kind)
+	err = Validate(&item{Name: "abc", Kind: &kind})
+	assert.EqualError(t, err, "kind: must be one of: box bag; mails: number of items must be at least 1")
+}
@@ -0,0 +1,164 @@
+package gorouter
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// paramConstraint checks the value of the url param while the tree is matched: "/item/:id<int>".
+// Known constraints are int, uint, uuid, alpha and enum(a,b,c), any other one is the regexp
+// which must match the whole segment: "/post/:slug<[a-z-]+>".
+type paramConstraint struct {
+	expr  string
+	check func(value string) bool
+}
+
+func parseConstraint(expr string) (*paramConstraint, error) {
+	out := &paramConstraint{expr: expr}
+
+	switch {
+	case expr == "":
+		return nil, fmt.Errorf("%w: empty param constraint", RouteSyntaxError)
+	case expr == "int":
+		out.check = isInt
+	case expr == "uint":
+		out.check = isUint
+	case expr == "uuid":
+		out.check = isUUID
+	case expr == "alpha":
+		out.check = isAlpha
+	case strings.HasPrefix(expr, "enum(") && strings.HasSuffix(expr, ")"):
+		values := map[string]bool{}
+		for _, v := range strings.Split(expr[len("enum("):len(expr)-1], ",") {
+			if v = strings.TrimSpace(v); v == "" {
+				return nil, fmt.Errorf("%w: empty value of the param constraint '%s'", RouteSyntaxError, expr)
+			}
+			values[v] = true
+		}
+		out.check = func(value string) bool { return values[value] }
+	default:
+		reg, err := regexp.Compile(`^(?:` + expr + `)$`)
+		if err != nil {
+			return nil, fmt.Errorf("%w: bad param constraint '%s': %s", RouteSyntaxError, expr, err.Error())
+		}
+		out.check = reg.MatchString
+	}
+
+	return out, nil
+}
+
+func (c *paramConstraint) match(value string) bool {
+	return c == nil || c.check(value)
+}
+
+func (c *paramConstraint) expression() string {
+	if c == nil {
+		return ""
+	}
+
+	return c.expr
+}
+
+func (c *paramConstraint) String() string {
+	if c == nil {
+		return ""
+	}
+
+	return "<" + c.expr + ">"
+}
+
+// splitParam splits the param segment without the leading colon into the name and the constraint: "id<int>".
+func splitParam(param string) (name, constraint string, err error) {
+	i := strings.IndexByte(param, '<')
+	if i < 0 {
+		return param, "", nil
+	}
+
+	if !strings.HasSuffix(param, ">") {
+		return "", "", fmt.Errorf("%w: unclosed constraint of param '%s'", RouteSyntaxError, param)
+	}
+
+	return param[:i], param[i+1 : len(param)-1], nil
+}
+
+// paramEnd returns the end of the param segment, slashes inside the constraint don't end it.
+func paramEnd(pattern string) int {
+	depth := 0
+	for i := 0; i < len(pattern); i++ {
+		switch pattern[i] {
+		case '<':
+			depth++
+		case '>':
+			if depth > 0 {
+				depth--
+			}
+		case '/':
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(pattern)
+}
+
+func isUint(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] < '0' || value[i] > '9' {
+			return false
+		}
+	}
+
+	return true
+}
+
+func isInt(value string) bool {
+	if strings.HasPrefix(value, "-") || strings.HasPrefix(value, "+") {
+		value = value[1:]
+	}
+
+	return isUint(value)
+}
+
+func isAlpha(value string) bool {
+	if value == "" {
+		return false
+	}
+
+	for _, r := range value {
+		if !unicode.IsLetter(r) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isUUID checks the canonical form: 8-4-4-4-12 hex digits.
+func isUUID(value string) bool {
+	if len(value) != 36 {
+		return false
+	}
+
+	for i := 0; i < len(value); i++ {
+		switch i {
+		case 8, 13, 18, 23:
+			if value[i] != '-' {
+				return false
+			}
+		default:
+			c := value[i]
+			if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f' || 'A' <= c && c <= 'F') {
+				return false
+			}
+		}
+	}
+
+	return true
+}
@@ -38,6 +38,7 @@ func (c Cookies) Get(name string) *http.Cookie {
 
 type Context struct {
 	baseCtx context.Context // global context
+	server  *Server         // server which runs the request
 
 	uniqId uint64 // uniq request id, for tests and debug purposes.
 
@@ -56,20 +57,44 @@ type Context struct {
 
 	logger *logger.Logger
 
+	uploads   UploadLimits // limits of multipart uploads of the route
+	tempFiles []string     // temp files of uploads, they are removed when the request finishes
+
+	sse *SSEWriter // event stream of the response
+
 	eg         *errgroup.Group
 	requestCtx context.Context
+	cancelCtx  context.Context // parent of requestCtx which is not canceled by EGWait
 	cancel     context.CancelFunc
 
 	handleDebugPipeline []string
 }
 
 func NewContext(baseCtx context.Context, fastCtx *fasthttp.RequestCtx, args *fasthttp.Args) (*Context, error) {
-	cCtx, cancel := context.WithCancel(fastCtx)
+	return newContext(baseCtx, fastCtx, args, 0)
+}
+
+// newContext creates the context of the request, the request context gets the deadline if the timeout is positive.
+func newContext(baseCtx context.Context, fastCtx *fasthttp.RequestCtx, args *fasthttp.Args, timeout time.Duration) (*Context, error) {
+	parent := baseCtx
+	if parent == nil {
+		parent = context.Background()
+	}
+	parent = requestValues{Context: parent, fastCtx: fastCtx}
+
+	var cCtx context.Context
+	var cancel context.CancelFunc
+	if timeout > 0 {
+		cCtx, cancel = context.WithTimeout(parent, timeout)
+	} else {
+		cCtx, cancel = context.WithCancel(parent)
+	}
 	eg, ctx := errgroup.WithContext(cCtx)
 
 	out := &Context{
 		fastCtx:             fastCtx,
 		requestCtx:          ctx,
+		cancelCtx:           cCtx,
 		baseCtx:             baseCtx,
 		data:                map[string]any{},
 		sameSite:            fasthttp.CookieSameSiteDisabled,
@@ -84,6 +109,23 @@ func NewContext(baseCtx context.Context, fastCtx *fasthttp.RequestCtx, args *fas
 	return out, nil
 }
 
+// requestValues is the parent of the request context: values come from the fasthttp request,
+// cancellation comes from the global context. fasthttp.RequestCtx.Done must not be watched by the goroutine
+// of context.WithCancel, the fasthttp server resets it on shutdown when the request is already finished.
+type requestValues struct {
+	context.Context
+
+	fastCtx *fasthttp.RequestCtx
+}
+
+func (c requestValues) Value(key any) any {
+	if v := c.fastCtx.Value(key); v != nil {
+		return v
+	}
+
+	return c.Context.Value(key)
+}
+
 func (ctx *Context) Clone() *Context {
 	data := map[string]any{}
 	for k := range ctx.data {
@@ -93,6 +135,7 @@ func (ctx *Context) Clone() *Context {
 	return &Context{
 		fastCtx:  ctx.fastCtx,
 		baseCtx:  ctx.baseCtx,
+		server:   ctx.server,
 		data:     data,
 		sameSite: ctx.sameSite,
 		urlIDs:   ctx.urlIDs,
@@ -300,6 +343,13 @@ func (ctx *Context) Stopped() bool {
 	return ctx.isAborted || ctx.isStopped
 }
 
+// TimedOut checks the deadline of the request context is exceeded. The handler is never interrupted,
+// the pipeline stops after the current handler returns and the request is answered 503
+// (504 if the handler returns context.DeadlineExceeded). Long handlers should watch ctx.Ctx().Done().
+func (ctx *Context) TimedOut() bool {
+	return ctx.requestCtx != nil && errors.Is(ctx.requestCtx.Err(), context.DeadlineExceeded)
+}
+
 func (ctx *Context) Debugf(format string, data ...any) {
 	if ctx.logger.Config().Level() >= level.DebugLevel {
 		logger.New().
@@ -378,12 +428,14 @@ func (ctx *Context) EGWait() error {
 	return ctx.eg.Wait()
 }
 
+// EGGo runs the function in the goroutine of the context errgroup, its panic is returned by EGWait as PanicError.
 func (ctx *Context) EGGo(f func() error) {
-	ctx.eg.Go(f)
+	ctx.eg.Go(func() error { return safeRun(f) })
 }
 
+// EGTryGo is EGGo which respects the limit of the errgroup, see errgroup.Group.TryGo.
 func (ctx *Context) EGTryGo(f func() error) bool {
-	return ctx.eg.TryGo(f)
+	return ctx.eg.TryGo(func() error { return safeRun(f) })
 }
 
 func (ctx *Context) EGSetLimit(n int) {
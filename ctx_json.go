@@ -0,0 +1,239 @@
+package gorouter
+
+import (
+	"bufio"
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"iter"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultJSONLimit is the max size of the JSON request body if the server has no own limit.
+const DefaultJSONLimit = 1 << 20
+
+var (
+	JSONEmptyBodyError    = errors.New("empty JSON body")
+	JSONBodyTooLargeError = errors.New("JSON body is too large")
+	JSONContentTypeError  = errors.New("not JSON content type")
+	JSONSyntaxError       = errors.New("JSON syntax error")
+	JSONUnknownFieldError = errors.New("unknown JSON field")
+	JSONTypeError         = errors.New("JSON value type error")
+)
+
+var (
+	jsonAPI       = json.ConfigCompatibleWithStandardLibrary
+	jsonStrictAPI = json.Config{
+		EscapeHTML:             true,
+		SortMapKeys:            true,
+		ValidateJsonRawMessage: true,
+		DisallowUnknownFields:  true,
+	}.Froze()
+
+	jsonpCallback = regexp.MustCompile(`^[a-zA-Z_$][0-9a-zA-Z_$.]*$`)
+)
+
+func (server *Server) JSONLimit() int {
+	return server.jsonLimit
+}
+
+// SetJSONLimit sets up the max size of the JSON request body for BindJSON, DefaultJSONLimit is used if it's zero.
+func (server *Server) SetJSONLimit(limit int) *Server {
+	server.jsonLimit = limit
+	return server
+}
+
+func (server *Server) JSONUnknownFields() bool {
+	return server.jsonUnknownFields
+}
+
+// SetJSONUnknownFields allows unknown fields of JSON request bodies, by default BindJSON rejects them.
+func (server *Server) SetJSONUnknownFields(allow bool) *Server {
+	server.jsonUnknownFields = allow
+	return server
+}
+
+// BindJSON decodes the JSON request body into v. Errors are HTTPError with the typed cause:
+// 413 for JSONBodyTooLargeError, 415 for JSONContentTypeError and 400 for other ones.
+// The body which is larger than the limit is never read as the whole.
+func (ctx *Context) BindJSON(v any) error {
+	limit, strict := DefaultJSONLimit, true
+	if ctx.server != nil {
+		if ctx.server.jsonLimit > 0 {
+			limit = ctx.server.jsonLimit
+		}
+		strict = !ctx.server.jsonUnknownFields
+	}
+
+	contentType := string(ctx.fastCtx.Request.Header.ContentType())
+	if contentType != "" && !strings.Contains(strings.ToLower(contentType), "json") {
+		return NewHTTPError(fasthttp.StatusUnsupportedMediaType, "JSON body is expected").
+			Wrap(fmt.Errorf("%w: '%s'", JSONContentTypeError, contentType))
+	}
+
+	body, err := ctx.jsonBody(limit)
+	if err != nil {
+		return err
+	}
+	if len(body) == 0 {
+		return BadRequest("JSON body is required").Wrap(JSONEmptyBodyError)
+	}
+
+	api := jsonAPI
+	if strict {
+		api = jsonStrictAPI
+	}
+
+	err = api.Unmarshal(body, v)
+	if err == nil {
+		return nil
+	}
+
+	// the body is checked again only to find out the cause of the error,
+	// encoding/json rejects trailing data after the value unlike jsoniter
+	if !stdjson.Valid(body) {
+		return BadRequest("invalid JSON body").Wrap(fmt.Errorf("%w: %s", JSONSyntaxError, err.Error()))
+	}
+
+	if strict {
+		if lenientErr := decodeLeniently(body, v); lenientErr != nil {
+			err = lenientErr
+		} else {
+			return BadRequest("JSON body has unknown fields").Wrap(fmt.Errorf("%w: %s", JSONUnknownFieldError, err.Error()))
+		}
+	}
+
+	return BadRequest("invalid value in JSON body").Wrap(fmt.Errorf("%w: %s", JSONTypeError, err.Error()))
+}
+
+// jsonBody returns the request body if it's not larger than the limit. Content-Length is checked first,
+// the streamed body (see fasthttp.Server.StreamRequestBody) is read up to the limit only.
+func (ctx *Context) jsonBody(limit int) ([]byte, error) {
+	tooLarge := NewHTTPError(fasthttp.StatusRequestEntityTooLarge, "JSON body must not be larger than "+strconv.Itoa(limit)+" bytes").
+		Wrap(JSONBodyTooLargeError)
+
+	req := &ctx.fastCtx.Request
+	if req.Header.ContentLength() > limit {
+		return nil, tooLarge
+	}
+
+	stream := ctx.fastCtx.RequestBodyStream()
+	if stream == nil {
+		if body := req.Body(); len(body) <= limit {
+			return body, nil
+		}
+		return nil, tooLarge
+	}
+
+	// one byte more to find out the body exceeds the limit
+	body, err := io.ReadAll(io.LimitReader(stream, int64(limit)+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(body) > limit {
+		return nil, tooLarge
+	}
+
+	// the body stays available for other readers
+	req.SetBody(body)
+
+	return body, nil
+}
+
+// decodeLeniently decodes the body into the new value of the type of v, unknown fields are allowed.
+func decodeLeniently(body []byte, v any) error {
+	t := reflect.TypeOf(v)
+	if t == nil || t.Kind() != reflect.Pointer {
+		return jsonAPI.Unmarshal(body, v)
+	}
+
+	return jsonAPI.Unmarshal(body, reflect.New(t.Elem()).Interface())
+}
+
+// JSON writes v as the JSON response with the status.
+func (ctx *Context) JSON(status int, v any) error {
+	body, err := jsonAPI.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx.fastCtx.SetStatusCode(status)
+	ctx.fastCtx.SetContentType(contentTypeJSON)
+	ctx.fastCtx.SetBody(body)
+
+	return nil
+}
+
+// JSONP writes v as the JSONP response, the name of the function is taken from "callback" param.
+// It's the usual JSON response if there is no callback.
+func (ctx *Context) JSONP(status int, v any) error {
+	callback := ctx.PeekStringParam("callback")
+	if callback == "" {
+		return ctx.JSON(status, v)
+	}
+
+	if !jsonpCallback.MatchString(callback) {
+		return BadRequest("invalid callback")
+	}
+
+	body, err := jsonAPI.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	ctx.fastCtx.SetStatusCode(status)
+	ctx.fastCtx.SetContentType("application/javascript; charset=utf-8")
+	ctx.fastCtx.SetBodyString("/**/" + callback + "(" + string(body) + ");")
+
+	return nil
+}
+
+// JSONStream writes items of the sequence as the JSON array, items are encoded and flushed one by one.
+// The sequence is read in its own goroutine while the response is sent, it must not use the request context
+// which is canceled when the handler pipeline finishes.
+// The stream is stopped if the client goes away or the item can't be encoded.
+func (ctx *Context) JSONStream(status int, seq iter.Seq[any]) {
+	ctx.fastCtx.SetStatusCode(status)
+	ctx.fastCtx.SetContentType(contentTypeJSON)
+
+	// the stream logs with its own copy, so its fields don't leak into other lines of the request logger
+	lg := ctx.Logger().Clone()
+	ctx.fastCtx.SetBodyStreamWriter(func(w *bufio.Writer) {
+		stream := jsonAPI.BorrowStream(w)
+		defer jsonAPI.ReturnStream(stream)
+
+		stream.WriteArrayStart()
+		first := true
+		for item := range seq {
+			if !first {
+				stream.WriteMore()
+			}
+			first = false
+
+			stream.WriteVal(item)
+			if stream.Error != nil {
+				lg.Error(stream.Error).Errorf("JSON stream is stopped")
+				return
+			}
+
+			if err := stream.Flush(); err != nil {
+				return
+			}
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+		stream.WriteArrayEnd()
+
+		if err := stream.Flush(); err == nil {
+			_ = w.Flush()
+		}
+	})
+}
@@ -0,0 +1,180 @@
+package gorouter
+
+import (
+	"errors"
+	"io"
+	"iter"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type jsonItem struct {
+	ID   int    `json:"id"`
+	Name string `json:"name"`
+}
+
+type BindJSONHandler struct {
+	RunHandler
+
+	Err error // the last error of BindJSON
+}
+
+func (h *BindJSONHandler) Run(ctx *Context) error {
+	item := &jsonItem{}
+	h.Err = ctx.BindJSON(item)
+	if h.Err != nil {
+		return h.Err
+	}
+
+	return ctx.JSON(fasthttp.StatusCreated, item)
+}
+
+type JSONPHandler struct {
+	RunHandler
+}
+
+func (h *JSONPHandler) Run(ctx *Context) error {
+	return ctx.JSONP(fasthttp.StatusOK, &jsonItem{ID: 1, Name: "a"})
+}
+
+type JSONStreamHandler struct {
+	RunHandler
+
+	Items []any
+}
+
+func (h *JSONStreamHandler) Run(ctx *Context) error {
+	var seq iter.Seq[any] = func(yield func(any) bool) {
+		for _, item := range h.Items {
+			if !yield(item) {
+				return
+			}
+		}
+	}
+
+	ctx.JSONStream(fasthttp.StatusOK, seq)
+	return nil
+}
+
+// endlessReader counts read bytes of the endless body.
+type endlessReader struct {
+	read int
+}
+
+func (r *endlessReader) Read(b []byte) (int, error) {
+	for i := range b {
+		b[i] = ' '
+	}
+	r.read += len(b)
+
+	return len(b), nil
+}
+
+func withBodyStream(body io.Reader, size int) func(req *fasthttp.Request) {
+	return func(req *fasthttp.Request) {
+		req.Header.SetContentType("application/json")
+		req.SetBodyStream(body, size)
+	}
+}
+
+func TestBindJSON(t *testing.T) {
+	h := &BindJSONHandler{}
+	g := New().SetJSONLimit(64)
+	g.Router().Post("/items", h)
+
+	fastCtx := serveTest(g, MethodPost, "/items", withBody("application/json; charset=utf-8", `{"id":15,"name":"box"}`))
+	assert.Nil(t, h.Err)
+	assert.EqualValues(t, fasthttp.StatusCreated, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, contentTypeJSON, string(fastCtx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"id":15,"name":"box"}`, string(fastCtx.Response.Body()))
+
+	data := []struct {
+		contentType, body string
+		code              int
+		err               error
+		message           string
+	}{
+		{"application/json", `{"id":15,"color":"red"}`, fasthttp.StatusBadRequest, JSONUnknownFieldError, "JSON body has unknown fields"},
+		{"application/json", `{"id":15,"name":{"first":"box"}}`, fasthttp.StatusBadRequest, JSONTypeError, "invalid value in JSON body"},
+		{"application/json", `{"id":15,"color":"red","name":3}`, fasthttp.StatusBadRequest, JSONTypeError, "invalid value in JSON body"},
+		{"application/json", `{"id":15,`, fasthttp.StatusBadRequest, JSONSyntaxError, "invalid JSON body"},
+		{"application/json", `{"id":15}garbage`, fasthttp.StatusBadRequest, JSONSyntaxError, "invalid JSON body"},
+		{"application/json", `{"id":15} {"id":16}`, fasthttp.StatusBadRequest, JSONSyntaxError, "invalid JSON body"},
+		{"application/json", `{"id":"15"}`, fasthttp.StatusBadRequest, JSONTypeError, "invalid value in JSON body"},
+		{"application/json", ``, fasthttp.StatusBadRequest, JSONEmptyBodyError, "JSON body is required"},
+		{"application/json", `{"name":"` + strings.Repeat("x", 64) + `"}`, fasthttp.StatusRequestEntityTooLarge, JSONBodyTooLargeError, "JSON body must not be larger than 64 bytes"},
+		{"text/plain", `{"id":15}`, fasthttp.StatusUnsupportedMediaType, JSONContentTypeError, "JSON body is expected"},
+	}
+
+	for _, d := range data {
+		fastCtx = serveTest(g, MethodPost, "/items", withBody(d.contentType, d.body))
+		assert.True(t, errors.Is(h.Err, d.err), d.body)
+		assert.EqualValues(t, d.code, fastCtx.Response.StatusCode(), d.body)
+		assert.EqualValues(t, d.message, string(fastCtx.Response.Body()), d.body)
+		assert.EqualValues(t, 1, strings.Count(h.Err.Error(), d.err.Error()), h.Err.Error())
+	}
+
+	// the type error isn't reported as the unknown field one
+	serveTest(g, MethodPost, "/items", withBody("application/json", `{"id":15,"color":"red","name":3}`))
+	assert.NotContains(t, h.Err.Error(), "unknown field")
+
+	// the streamed body is read up to the limit only
+	endless := &endlessReader{}
+	fastCtx = serveTest(g, MethodPost, "/items", withBodyStream(endless, -1))
+	assert.True(t, errors.Is(h.Err, JSONBodyTooLargeError))
+	assert.EqualValues(t, fasthttp.StatusRequestEntityTooLarge, fastCtx.Response.StatusCode())
+	assert.LessOrEqual(t, endless.read, 65)
+
+	// the body isn't read at all if Content-Length exceeds the limit
+	endless = &endlessReader{}
+	serveTest(g, MethodPost, "/items", withBodyStream(endless, 65))
+	assert.True(t, errors.Is(h.Err, JSONBodyTooLargeError))
+	assert.Zero(t, endless.read)
+
+	fastCtx = serveTest(g, MethodPost, "/items", withBodyStream(strings.NewReader(`{"id":15,"name":"box"}`), -1))
+	assert.Nil(t, h.Err)
+	assert.JSONEq(t, `{"id":15,"name":"box"}`, string(fastCtx.Response.Body()))
+
+	// unknown fields are skipped if they are allowed
+	g.SetJSONUnknownFields(true)
+	fastCtx = serveTest(g, MethodPost, "/items", withBody("application/json", `{"id":15,"color":"red"}`))
+	assert.Nil(t, h.Err)
+	assert.JSONEq(t, `{"id":15,"name":""}`, string(fastCtx.Response.Body()))
+}
+
+func TestJSONP(t *testing.T) {
+	g := New()
+	g.Router().Get("/item", &JSONPHandler{})
+
+	fastCtx := serveTest(g, MethodGet, "/item?callback=app.show")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "application/javascript; charset=utf-8", string(fastCtx.Response.Header.ContentType()))
+	assert.EqualValues(t, `/**/app.show({"id":1,"name":"a"});`, string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/item")
+	assert.EqualValues(t, contentTypeJSON, string(fastCtx.Response.Header.ContentType()))
+	assert.EqualValues(t, `{"id":1,"name":"a"}`, string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/item?callback=alert(1)")
+	assert.EqualValues(t, fasthttp.StatusBadRequest, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "invalid callback", string(fastCtx.Response.Body()))
+}
+
+func TestJSONStream(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/items", &JSONStreamHandler{Items: []any{&jsonItem{ID: 1, Name: "a"}, map[string]int{"id": 2}, 3}}).
+		Get("/empty", &JSONStreamHandler{})
+
+	fastCtx := serveTest(g, MethodGet, "/items")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, contentTypeJSON, string(fastCtx.Response.Header.ContentType()))
+	assert.True(t, fastCtx.Response.IsBodyStream())
+	assert.EqualValues(t, `[{"id":1,"name":"a"},{"id":2},3]`, string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/empty")
+	assert.EqualValues(t, `[]`, string(fastCtx.Response.Body()))
+}
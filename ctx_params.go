@@ -0,0 +1,327 @@
+package gorouter
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ParamMissingError = errors.New("missing param")
+	ParamTypeError    = errors.New("invalid param value")
+)
+
+// ParamSource restricts the lookup of params.
+type ParamSource int
+
+const (
+	ParamAny   ParamSource = iota // path, query and form params in the order of PeekParam
+	ParamPath                     // url params of the route
+	ParamQuery                    // query args
+	ParamForm                     // post args
+)
+
+func (source ParamSource) String() string {
+	switch source {
+	case ParamPath:
+		return "path"
+	case ParamQuery:
+		return "query"
+	case ParamForm:
+		return "form"
+	}
+
+	return "any"
+}
+
+// ParamReader converts params of the single source. Methods without "Or" return HTTPError 400
+// with ParamMissingError or ParamTypeError cause, "Or" methods return the default value on any error.
+type ParamReader struct {
+	ctx    *Context
+	source ParamSource
+}
+
+// Params returns the reader of params of the source, for example, ctx.Params(ParamQuery).IntOr("page", 1).
+func (ctx *Context) Params(source ParamSource) ParamReader {
+	return ParamReader{ctx: ctx, source: source}
+}
+
+func (r ParamReader) peek(key string) []byte {
+	switch r.source {
+	case ParamPath:
+		if r.ctx.urlIDs == nil {
+			return nil
+		}
+		return r.ctx.urlIDs.Peek(key)
+	case ParamQuery:
+		return r.ctx.fastCtx.QueryArgs().Peek(key)
+	case ParamForm:
+		return r.ctx.fastCtx.PostArgs().Peek(key)
+	}
+
+	return r.ctx.PeekParam(key)
+}
+
+func (r ParamReader) value(key string) (string, error) {
+	value := r.peek(key)
+	if len(value) == 0 {
+		return "", BadRequest("param '" + key + "' is required").
+			Wrap(fmt.Errorf("%w: '%s', source %s", ParamMissingError, key, r.source))
+	}
+
+	return string(value), nil
+}
+
+func (r ParamReader) typeError(key, value, expected string) error {
+	return BadRequest("invalid param '" + key + "'").
+		Wrap(fmt.Errorf("%w: '%s' is '%s', %s is expected", ParamTypeError, key, value, expected))
+}
+
+func (r ParamReader) Int(key string) (int, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, r.typeError(key, value, "int")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) IntOr(key string, def int) int {
+	if out, err := r.Int(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+func (r ParamReader) Int64(key string) (int64, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return 0, r.typeError(key, value, "int64")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) Int64Or(key string, def int64) int64 {
+	if out, err := r.Int64(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+func (r ParamReader) Uint(key string) (uint64, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := strconv.ParseUint(value, 10, 64)
+	if err != nil {
+		return 0, r.typeError(key, value, "uint")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) UintOr(key string, def uint64) uint64 {
+	if out, err := r.Uint(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+func (r ParamReader) Float(key string) (float64, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, r.typeError(key, value, "float")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) FloatOr(key string, def float64) float64 {
+	if out, err := r.Float(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+// Duration parses the param by time.ParseDuration: "300ms", "1h30m".
+func (r ParamReader) Duration(key string) (time.Duration, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return 0, err
+	}
+
+	out, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, r.typeError(key, value, "duration")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) DurationOr(key string, def time.Duration) time.Duration {
+	if out, err := r.Duration(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+// Time parses the param with the layout, for example, time.RFC3339 or time.DateOnly.
+func (r ParamReader) Time(key, layout string) (time.Time, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	out, err := time.Parse(layout, value)
+	if err != nil {
+		return time.Time{}, r.typeError(key, value, "time '"+layout+"'")
+	}
+
+	return out, nil
+}
+
+func (r ParamReader) TimeOr(key, layout string, def time.Time) time.Time {
+	if out, err := r.Time(key, layout); err == nil {
+		return out
+	}
+
+	return def
+}
+
+// UUID returns the param in the canonical form: 8-4-4-4-12 hex digits, in lower case.
+func (r ParamReader) UUID(key string) (string, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return "", err
+	}
+
+	if !isUUID(value) {
+		return "", r.typeError(key, value, "uuid")
+	}
+
+	return strings.ToLower(value), nil
+}
+
+func (r ParamReader) UUIDOr(key, def string) string {
+	if out, err := r.UUID(key); err == nil {
+		return out
+	}
+
+	return def
+}
+
+// Enum returns the param if it's one of the values.
+func (r ParamReader) Enum(key string, values ...string) (string, error) {
+	value, err := r.value(key)
+	if err != nil {
+		return "", err
+	}
+
+	for _, v := range values {
+		if v == value {
+			return value, nil
+		}
+	}
+
+	return "", r.typeError(key, value, "one of "+strings.Join(values, ", "))
+}
+
+func (r ParamReader) EnumOr(key, def string, values ...string) string {
+	if out, err := r.Enum(key, values...); err == nil {
+		return out
+	}
+
+	return def
+}
+
+// ParamInt and other typed getters below look up the param like PeekParam: path, query and form params.
+// Use ctx.Params(source) to restrict the lookup to the single source.
+
+func (ctx *Context) ParamInt(key string) (int, error) {
+	return ctx.Params(ParamAny).Int(key)
+}
+
+func (ctx *Context) ParamIntOr(key string, def int) int {
+	return ctx.Params(ParamAny).IntOr(key, def)
+}
+
+func (ctx *Context) ParamInt64(key string) (int64, error) {
+	return ctx.Params(ParamAny).Int64(key)
+}
+
+func (ctx *Context) ParamInt64Or(key string, def int64) int64 {
+	return ctx.Params(ParamAny).Int64Or(key, def)
+}
+
+func (ctx *Context) ParamUint(key string) (uint64, error) {
+	return ctx.Params(ParamAny).Uint(key)
+}
+
+func (ctx *Context) ParamUintOr(key string, def uint64) uint64 {
+	return ctx.Params(ParamAny).UintOr(key, def)
+}
+
+func (ctx *Context) ParamFloat(key string) (float64, error) {
+	return ctx.Params(ParamAny).Float(key)
+}
+
+func (ctx *Context) ParamFloatOr(key string, def float64) float64 {
+	return ctx.Params(ParamAny).FloatOr(key, def)
+}
+
+func (ctx *Context) ParamDuration(key string) (time.Duration, error) {
+	return ctx.Params(ParamAny).Duration(key)
+}
+
+func (ctx *Context) ParamDurationOr(key string, def time.Duration) time.Duration {
+	return ctx.Params(ParamAny).DurationOr(key, def)
+}
+
+func (ctx *Context) ParamTime(key, layout string) (time.Time, error) {
+	return ctx.Params(ParamAny).Time(key, layout)
+}
+
+func (ctx *Context) ParamTimeOr(key, layout string, def time.Time) time.Time {
+	return ctx.Params(ParamAny).TimeOr(key, layout, def)
+}
+
+func (ctx *Context) ParamUUID(key string) (string, error) {
+	return ctx.Params(ParamAny).UUID(key)
+}
+
+func (ctx *Context) ParamUUIDOr(key, def string) string {
+	return ctx.Params(ParamAny).UUIDOr(key, def)
+}
+
+func (ctx *Context) ParamEnum(key string, values ...string) (string, error) {
+	return ctx.Params(ParamAny).Enum(key, values...)
+}
+
+func (ctx *Context) ParamEnumOr(key, def string, values ...string) string {
+	return ctx.Params(ParamAny).EnumOr(key, def, values...)
+}
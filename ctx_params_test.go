@@ -0,0 +1,116 @@
+package gorouter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type TypedParamsHandler struct {
+	RunHandler
+
+	Check func(ctx *Context)
+}
+
+func (h *TypedParamsHandler) Run(ctx *Context) error {
+	h.Check(ctx)
+	return nil
+}
+
+func serveParamsTest(t *testing.T, uri, form string, check func(ctx *Context)) {
+	g := New()
+	g.Router().Post("/items/:id", &TypedParamsHandler{Check: check})
+
+	fastCtx := serveTest(g, MethodPost, uri, withForm(form))
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), uri)
+}
+
+func TestTypedParams(t *testing.T) {
+	const id = "7C9E6679-7425-40DE-944B-E07FC1F90AE7"
+	uri := "/items/15?page=2&big=9000000000&ratio=0.5&wait=1m&day=2024-05-01&sort=name&uuid=" + id
+
+	serveParamsTest(t, uri, "limit=30", func(ctx *Context) {
+		i, err := ctx.ParamInt("id")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 15, i)
+		assert.EqualValues(t, 30, ctx.ParamIntOr("limit", 10))
+		assert.EqualValues(t, 10, ctx.ParamIntOr("sort", 10))
+
+		i64, err := ctx.ParamInt64("big")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 9000000000, i64)
+		assert.EqualValues(t, -1, ctx.ParamInt64Or("none", -1))
+
+		u, err := ctx.ParamUint("page")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 2, u)
+		assert.EqualValues(t, 7, ctx.ParamUintOr("ratio", 7))
+
+		f, err := ctx.ParamFloat("ratio")
+		assert.Nil(t, err)
+		assert.EqualValues(t, 0.5, f)
+		assert.EqualValues(t, 1.5, ctx.ParamFloatOr("sort", 1.5))
+
+		d, err := ctx.ParamDuration("wait")
+		assert.Nil(t, err)
+		assert.EqualValues(t, time.Minute, d)
+		assert.EqualValues(t, time.Second, ctx.ParamDurationOr("page", time.Second))
+
+		day, err := ctx.ParamTime("day", time.DateOnly)
+		assert.Nil(t, err)
+		assert.EqualValues(t, time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC), day)
+		assert.EqualValues(t, time.Time{}, ctx.ParamTimeOr("day", time.RFC3339, time.Time{}))
+
+		uuid, err := ctx.ParamUUID("uuid")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "7c9e6679-7425-40de-944b-e07fc1f90ae7", uuid)
+		assert.EqualValues(t, "none", ctx.ParamUUIDOr("id", "none"))
+
+		sort, err := ctx.ParamEnum("sort", "id", "name")
+		assert.Nil(t, err)
+		assert.EqualValues(t, "name", sort)
+		assert.EqualValues(t, "id", ctx.ParamEnumOr("page", "id", "id", "name"))
+	})
+}
+
+func TestTypedParamsErrors(t *testing.T) {
+	serveParamsTest(t, "/items/abc?day=2024", "", func(ctx *Context) {
+		_, err := ctx.ParamInt("id")
+		assert.True(t, errors.Is(err, ParamTypeError))
+		assert.EqualError(t, err, "400 invalid param 'id': invalid param value: 'id' is 'abc', int is expected")
+		assert.EqualValues(t, fasthttp.StatusBadRequest, asHTTPError(err).Code)
+
+		_, err = ctx.ParamUint("page")
+		assert.True(t, errors.Is(err, ParamMissingError))
+		assert.EqualError(t, err, "400 param 'page' is required: missing param: 'page', source any")
+
+		_, err = ctx.ParamTime("day", time.DateOnly)
+		assert.True(t, errors.Is(err, ParamTypeError))
+
+		_, err = ctx.ParamEnum("id", "a", "b")
+		assert.EqualError(t, err, "400 invalid param 'id': invalid param value: 'id' is 'abc', one of a, b is expected")
+	})
+}
+
+func TestParamSource(t *testing.T) {
+	serveParamsTest(t, "/items/1?id=2&page=3", "id=4&page=5&limit=6", func(ctx *Context) {
+		assert.EqualValues(t, 1, ctx.ParamIntOr("id", 0))
+		assert.EqualValues(t, 1, ctx.Params(ParamPath).IntOr("id", 0))
+		assert.EqualValues(t, 2, ctx.Params(ParamQuery).IntOr("id", 0))
+		assert.EqualValues(t, 4, ctx.Params(ParamForm).IntOr("id", 0))
+
+		assert.EqualValues(t, 3, ctx.ParamIntOr("page", 0))
+		assert.EqualValues(t, 5, ctx.Params(ParamForm).IntOr("page", 0))
+		assert.EqualValues(t, 0, ctx.Params(ParamPath).IntOr("page", 0))
+
+		_, err := ctx.Params(ParamQuery).Int("limit")
+		assert.True(t, errors.Is(err, ParamMissingError))
+		assert.EqualError(t, err, "400 param 'limit' is required: missing param: 'limit', source query")
+
+		assert.EqualValues(t, "path query form any", fmt.Sprint(ParamPath, ParamQuery, ParamForm, ParamAny))
+	})
+}
@@ -2,16 +2,34 @@ package gorouter
 
 import (
 	"regexp"
+	"regexp/syntax"
+	"strings"
 	"sync"
+	"time"
 )
 
 type Router struct {
 	sync.RWMutex
 
 	server *Server
+	prefix string // path prefix of the group
+	host   string // host pattern of routes
 	before []IHandler
 	after  []IHandler
 	last   ILastHandler
+
+	// timeout is the deadline of requests, the server default is used if it's zero
+	timeout time.Duration
+	// uploads are limits of multipart uploads, the server default is used if it's nil
+	uploads *UploadLimits
+
+	// lastRoute is the last route which is added by the router
+	lastRoute *Route
+
+	// routes are added by the router and its groups, they are the module of the router
+	routesLock sync.Mutex
+	routes     []*Route
+	parent     *Router
 }
 
 func newRouter(server *Server) *Router {
@@ -76,12 +94,201 @@ func (router *Router) LastAfter(h IHandler) *Router {
 	return router
 }
 
+// AddBefore appends handlers to "before" handlers, it's useful to extend the chain of the group.
+func (router *Router) AddBefore(h ...IHandler) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	router.before = append(router.before, h...)
+
+	return router
+}
+
+// AddAfter appends handlers to "after" handlers, it's useful to extend the chain of the group.
+func (router *Router) AddAfter(h ...IHandler) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	router.after = append(router.after, h...)
+
+	return router
+}
+
+// Timeout sets up the deadline of requests for all next routes of the router.
+func (router *Router) Timeout(timeout time.Duration) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	router.timeout = timeout
+
+	return router
+}
+
+// UploadLimits sets up the limits of multipart uploads for all next routes of the router, see Context.FormFile.
+func (router *Router) UploadLimits(limits UploadLimits) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	router.uploads = &limits
+
+	return router
+}
+
+func (router *Router) Prefix() string {
+	return router.prefix
+}
+
+// Group returns the new router with the path prefix, it inherits all handlers of the router.
+// Groups may be nested: router.Group("/api").Group("/v2").
+func (router *Router) Group(prefix string) *Router {
+	out := router.Clone()
+	out.prefix = joinPath(router.prefix, prefix)
+	out.parent = router
+
+	return out
+}
+
+func (router *Router) HostPattern() string {
+	return router.host
+}
+
+// Host returns the new router for the host pattern, it inherits all handlers and the prefix of the router.
+// The pattern is the exact host "api.example.com", the wildcard "*.example.com" which matches
+// one or more labels, or the host with params ":tenant.example.com", params are added to UrlIds.
+// Routes of other matched patterns and routes without host are used if the host has no route for the path.
+func (router *Router) Host(host string) *Router {
+	out := router.Clone()
+	out.host = host
+	out.parent = router
+
+	return out
+}
+
+// Module is a set of routes which may be mounted into the router.
+type Module interface {
+	ModuleRoutes() []*Route
+}
+
+// ModuleRoutes returns all routes of the server.
+func (server *Server) ModuleRoutes() []*Route {
+	return server.RouteBuilder().Routes()
+}
+
+// ModuleRoutes returns routes which are added by the router and its groups, other routes of the server are not included.
+func (router *Router) ModuleRoutes() []*Route {
+	router.routesLock.Lock()
+	defer router.routesLock.Unlock()
+
+	return append([]*Route{}, router.routes...)
+}
+
+// track records routes which are added by the router, they are recorded by routers of all parent groups too.
+func (router *Router) track(routes ...*Route) {
+	for r := router; r != nil; r = r.parent {
+		r.routesLock.Lock()
+		r.routes = append(r.routes, routes...)
+		r.routesLock.Unlock()
+	}
+}
+
+// ModuleRoutes returns all routes of the builder.
+func (b *RouteBuilder) ModuleRoutes() []*Route {
+	return b.Routes()
+}
+
+// Mount adds all routes of the independently built module with the path prefix.
+// Handlers of the router wrap handlers of the module: "before" handlers go first, "after" handlers go last.
+func (router *Router) Mount(prefix string, module Module) *Router {
+	// the module may be the router itself
+	moduleRoutes := module.ModuleRoutes()
+
+	router.Lock()
+	defer router.Unlock()
+
+	prefix = joinPath(router.prefix, prefix)
+	routes := make([]*Route, 0, len(moduleRoutes))
+	for _, route := range moduleRoutes {
+		set := Set(route.HandlerSet.ID).Before(router.before...).Last(router.last).Timeout(router.timeout).uploadLimits(router.uploads)
+		set = set.mount(route.HandlerSet).After(router.after...)
+
+		router.lastRoute = &Route{
+			Method:     route.Method,
+			Host:       route.Host,
+			Priority:   route.Priority,
+			Name:       route.Name,
+			HandlerSet: set,
+		}
+
+		if router.host != "" {
+			router.lastRoute.Host = router.host
+		}
+
+		if route.Reg != nil {
+			router.lastRoute.Reg = prefixReg(prefix, route.Reg)
+		} else {
+			router.lastRoute.Path = joinPath(prefix, route.Path)
+		}
+
+		routes = append(routes, router.lastRoute)
+	}
+
+	router.server.addRoutes(routes...)
+	router.track(routes...)
+
+	return router
+}
+
+// joinPath adds the prefix to the path: "/api/" + "/user/:id" => "/api/user/:id".
+// The trailing slash of the path is kept, the trailing slash of the prefix is not.
+func joinPath(prefix, path string) string {
+	prefix = strings.Trim(prefix, "/")
+	if prefix == "" {
+		return "/" + strings.TrimLeft(path, "/")
+	}
+
+	path = strings.TrimLeft(path, "/")
+	if path == "" {
+		return "/" + prefix
+	}
+
+	return "/" + prefix + "/" + path
+}
+
+// prefixReg adds the prefix to the regexp, the result is always anchored at the start.
+// The leading "^" of the regexp is replaced by the prefix: "^/item/\d+$" => "^/api(?:/item/\d+$)",
+// the unanchored regexp matches anywhere after the prefix as before: "/item/\d+" => "^/api(?s:.*?)(?:/item/\d+)".
+func prefixReg(prefix string, reg *regexp.Regexp) *regexp.Regexp {
+	if prefix == "" || prefix == "/" {
+		return reg
+	}
+
+	head := "^" + regexp.QuoteMeta(strings.TrimRight(prefix, "/"))
+
+	// reg is compiled already, it's parsed with the same flags without errors
+	re, err := syntax.Parse(reg.String(), syntax.Perl)
+	if err != nil {
+		panic(err)
+	}
+
+	switch {
+	case re.Op == syntax.OpBeginText:
+		return regexp.MustCompile(head)
+	case re.Op == syntax.OpConcat && re.Sub[0].Op == syntax.OpBeginText:
+		re.Sub = re.Sub[1:]
+		return regexp.MustCompile(head + "(?:" + re.String() + ")")
+	}
+
+	return regexp.MustCompile(head + "(?s:.*?)(?:" + re.String() + ")")
+}
+
 func (router *Router) Use(method Method, route string, handler IRunHandler) *Router {
 	router.Lock()
 	defer router.Unlock()
 
-	set := Set("").After(router.after...).Before(router.before...).Last(router.last).Use(handler)
-	router.server.tree.Add(method, route, set)
+	set := Set("").After(router.after...).Before(router.before...).Last(router.last).Timeout(router.timeout).uploadLimits(router.uploads).Use(handler)
+	router.lastRoute = &Route{Method: method, Host: router.host, Path: joinPath(router.prefix, route), HandlerSet: set}
+	router.server.addRoute(router.lastRoute)
+	router.track(router.lastRoute)
 
 	return router
 }
@@ -90,12 +297,51 @@ func (router *Router) UseReg(method Method, route *regexp.Regexp, handler IRunHa
 	router.Lock()
 	defer router.Unlock()
 
-	set := Set("").After(router.after...).Before(router.before...).Last(router.last).Use(handler)
-	router.server.regTree.Add(method, route, set)
+	set := Set("").After(router.after...).Before(router.before...).Last(router.last).Timeout(router.timeout).uploadLimits(router.uploads).Use(handler)
+	router.lastRoute = &Route{Method: method, Host: router.host, Reg: prefixReg(router.prefix, route), HandlerSet: set}
+	router.server.addRoute(router.lastRoute)
+	router.track(router.lastRoute)
 
 	return router
 }
 
+// UseRegPriority adds the regexp route which is checked before all routes with the lower priority.
+func (router *Router) UseRegPriority(method Method, route *regexp.Regexp, priority int, handler IRunHandler) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	set := Set("").After(router.after...).Before(router.before...).Last(router.last).Timeout(router.timeout).uploadLimits(router.uploads).Use(handler)
+	router.lastRoute = &Route{Method: method, Host: router.host, Reg: prefixReg(router.prefix, route), Priority: priority, HandlerSet: set}
+	router.server.addRoute(router.lastRoute)
+	router.track(router.lastRoute)
+
+	return router
+}
+
+// Name sets up the name of the last route which is added by the router. The name is used to build URLs.
+func (router *Router) Name(name string) *Router {
+	router.Lock()
+	defer router.Unlock()
+
+	router.server.nameRoute(router.lastRoute, name)
+
+	return router
+}
+
+// Match adds the route for all methods of the list, standard and extension ones: "PROPFIND", "PURGE".
+func (router *Router) Match(methods []Method, route string, handler IRunHandler) *Router {
+	for _, method := range methods {
+		router.Use(method, route, handler)
+	}
+
+	return router
+}
+
+// Any adds the route for all standard methods, see StandardMethods.
+func (router *Router) Any(route string, handler IRunHandler) *Router {
+	return router.Match(StandardMethods, route, handler)
+}
+
 func (router *Router) GetPost(route string, handler IRunHandler) *Router {
 	router.Use(MethodGetPost, route, handler)
 
@@ -157,11 +403,18 @@ func (router *Router) Trace(route string, handler IRunHandler) *Router {
 }
 
 func (router *Router) Clone() *Router {
+	router.RLock()
+	defer router.RUnlock()
+
 	out := &Router{
-		server: router.server,
-		last:   router.last,
-		before: router.before[:],
-		after:  router.after[:],
+		server:  router.server,
+		prefix:  router.prefix,
+		host:    router.host,
+		last:    router.last,
+		before:  append([]IHandler{}, router.before...),
+		after:   append([]IHandler{}, router.after...),
+		timeout: router.timeout,
+		uploads: router.uploads,
 	}
 
 	return out
@@ -2,6 +2,7 @@ package gorouter
 
 import (
 	"context"
+	"net"
 	"net/http"
 	"testing"
 	"time"
@@ -50,11 +51,16 @@ func TestServer_1(t *testing.T) {
 	routerB.Use(MethodGet, "/", newIH("for MethodGet '/'"))
 	routerB.Use(MethodGet, "/a", newIH("for MethodGet '/a'"))
 
+	// listen before running the server, so the request below can't outrun it
+	ln, err := net.Listen("tcp", ":8081")
+	assert.Nil(t, err)
+	g.SetListener(ln)
+
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second*1)
 	wg, ctxEr := errgroup.WithContext(ctx)
 	wg.Go(func() error {
 		defer cancel()
-		return g.Run(ctxEr, ":8081")
+		return g.Run(ctxEr, "")
 	})
 
 	res, err := http.Get("http://localhost:8081/")
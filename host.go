@@ -0,0 +1,131 @@
+package gorouter
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+type hostKind int
+
+const (
+	hostExact hostKind = iota
+	hostParam
+	hostWildcard
+)
+
+// hostPattern is the parsed host of the route: "api.example.com", ":tenant.example.com" or "*.example.com".
+// The wildcard is the first label only and matches one or more labels, the param matches the single label.
+type hostPattern struct {
+	pattern string
+	kind    hostKind
+	labels  []string
+}
+
+func parseHost(pattern string) (*hostPattern, error) {
+	out := &hostPattern{
+		pattern: normalizeHost(pattern),
+		kind:    hostExact,
+	}
+
+	if out.pattern == "" {
+		return nil, fmt.Errorf("%w: empty host", RouteSyntaxError)
+	}
+
+	out.labels = strings.Split(out.pattern, ".")
+	for i, label := range out.labels {
+		switch {
+		case label == "":
+			return nil, fmt.Errorf("%w: empty label of host '%s'", RouteSyntaxError, pattern)
+		case label == "*":
+			if i != 0 {
+				return nil, fmt.Errorf("%w: wildcard must be the first label of host '%s'", RouteSyntaxError, pattern)
+			}
+			out.kind = hostWildcard
+		case label[0] == ':':
+			if len(label) == 1 {
+				return nil, fmt.Errorf("%w: empty param name of host '%s'", RouteSyntaxError, pattern)
+			}
+			if out.kind == hostExact {
+				out.kind = hostParam
+			}
+		}
+	}
+
+	return out, nil
+}
+
+// normalizeHost drops the port and the trailing dot, the host is case-insensitive.
+func normalizeHost(host string) string {
+	return strings.TrimSuffix(strings.ToLower(string(splitHost([]byte(host)))), ".")
+}
+
+// match checks the host and adds captured params to args if args is not nil. The host must be normalized.
+func (hp *hostPattern) match(host string, args *fasthttp.Args) bool {
+	if hp.kind == hostExact {
+		return host == hp.pattern
+	}
+
+	full, labels := host, hp.labels
+	if hp.kind == hostWildcard {
+		labels = labels[1:]
+	}
+
+	// the tail of host is compared with the pattern, the wildcard takes the head
+	for i := len(labels) - 1; i >= 0; i-- {
+		dot := strings.LastIndexByte(host, '.')
+		label := host[dot+1:]
+		if label == "" || (labels[i][0] != ':' && labels[i] != label) {
+			return false
+		}
+
+		if dot < 0 {
+			host = ""
+			if i > 0 {
+				return false
+			}
+		} else {
+			host = host[:dot]
+		}
+	}
+
+	if hp.kind == hostWildcard {
+		if host == "" {
+			return false
+		}
+	} else if host != "" {
+		return false
+	}
+
+	if args != nil {
+		hp.capture(full, args)
+	}
+
+	return true
+}
+
+// capture adds params of the matched host to args.
+func (hp *hostPattern) capture(host string, args *fasthttp.Args) {
+	labels := strings.Split(host, ".")
+	shift := len(labels) - len(hp.labels)
+	for i, label := range hp.labels {
+		if label[0] == ':' {
+			args.Add(label[1:], labels[i+shift])
+		}
+	}
+}
+
+// hostTable is the route table for the host pattern.
+type hostTable struct {
+	host  *hostPattern
+	table *RouteTable
+}
+
+// sortHosts puts exact hosts first, then hosts with params and wildcards, registration order is kept.
+func sortHosts(hosts []*hostTable) {
+	sort.SliceStable(hosts, func(i, j int) bool {
+		return hosts[i].host.kind < hosts[j].host.kind
+	})
+}
@@ -0,0 +1,134 @@
+package gorouter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHostPattern(t *testing.T) {
+	data := map[string]map[string]string{
+		"api.example.com": {
+			"api.example.com": "",
+			"API.Example.com": "",
+			"x.example.com":   "-",
+		},
+		":tenant.example.com": {
+			"acme.example.com":   "tenant=acme",
+			"a.acme.example.com": "-",
+			"example.com":        "-",
+		},
+		"*.example.com": {
+			"a.example.com":   "",
+			"a.b.example.com": "",
+			"example.com":     "-",
+			"a.example.org":   "-",
+		},
+		"*.:tenant.example.com": {
+			"a.acme.example.com":   "tenant=acme",
+			"a.b.acme.example.com": "tenant=acme",
+			"acme.example.com":     "-",
+		},
+	}
+
+	for pattern, hosts := range data {
+		hp, err := parseHost(pattern)
+		assert.Nil(t, err, pattern)
+
+		for host, expected := range hosts {
+			args := &fasthttp.Args{}
+			find := hp.match(normalizeHost(host), args)
+			if expected == "-" {
+				assert.False(t, find, pattern+" "+host)
+				continue
+			}
+
+			assert.True(t, find, pattern+" "+host)
+			assert.EqualValues(t, expected, args.String(), pattern+" "+host)
+		}
+	}
+
+	for _, pattern := range []string{"api..com", "a.*.com", ":.example.com", "."} {
+		_, err := parseHost(pattern)
+		assert.True(t, errors.Is(err, RouteSyntaxError), pattern)
+	}
+}
+
+func TestServerHost(t *testing.T) {
+	g := New()
+	g.Host("api.example.com").Get("/user/:id", newWH("api user"))
+	g.Host("*.example.com").Get("/user/:id", newWH("wildcard user"))
+	g.Host(":tenant.example.org").Get("/user/:id", &ParamsHandler{Keys: []string{"id", "tenant"}})
+	g.Router().Get("/user/:id", newWH("fallback user"))
+
+	data := map[string]string{
+		"api.example.com":      "api user",
+		"api.example.com:8080": "api user",
+		"x.y.example.com":      "wildcard user",
+		"other.com":            "fallback user",
+		"acme.example.org":     "id=15,tenant=acme",
+	}
+
+	for host, body := range data {
+		fastCtx := serveTest(g, MethodGet, "/user/15", withHeader(fasthttp.HeaderHost, host))
+		assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), host)
+		assert.EqualValues(t, body, string(fastCtx.Response.Body()), host)
+	}
+
+	// the matched host falls back to routes without host if it has no own route
+	g.Router().Get("/about", newWH("fallback about"))
+	g.Router().Delete("/user/:id", newWH("fallback delete"))
+
+	fastCtx := serveTest(g, MethodGet, "/about", withHeader(fasthttp.HeaderHost, "api.example.com"))
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "fallback about", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodDelete, "/user/15", withHeader(fasthttp.HeaderHost, "api.example.com"))
+	assert.EqualValues(t, "fallback delete", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/unknown", withHeader(fasthttp.HeaderHost, "api.example.com"))
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	fastCtx = serveTest(g, MethodPost, "/user/15", withHeader(fasthttp.HeaderHost, "api.example.com"))
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "DELETE, GET", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	// the same route for other hosts isn't the conflict
+	assert.Empty(t, g.RouteErrors())
+	g.Host("API.example.com").Get("/user/:id", newWH("api user 2"))
+	assert.Len(t, g.RouteErrors(), 1)
+
+	infos := g.Routes()
+	assert.EqualValues(t, "api.example.com", infos[0].Host)
+	assert.EqualValues(t, "", infos[3].Host)
+}
+
+func TestServerHostPriority(t *testing.T) {
+	g := New()
+	g.Host("api.example.com").Get("/x", newWH("api x"))
+	g.Host(":id.example.com").Get("/z", &ParamsHandler{Keys: []string{"id"}})
+	g.Host("*.example.com").Get("/y", newWH("wildcard y")).Get("/z", newWH("wildcard z"))
+	g.Router().Get("/w", newWH("fallback w"))
+
+	// all matched hosts are checked in priority order before routes without host
+	data := map[[2]string]string{
+		{"api.example.com", "/x"}:  "api x",
+		{"api.example.com", "/y"}:  "wildcard y",
+		{"api.example.com", "/z"}:  "id=api",
+		{"api.example.com", "/w"}:  "fallback w",
+		{"acme.example.com", "/y"}: "wildcard y",
+		{"a.b.example.com", "/z"}:  "wildcard z",
+	}
+
+	for in, body := range data {
+		fastCtx := serveTest(g, MethodGet, in[1], withHeader(fasthttp.HeaderHost, in[0]))
+		assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), in)
+		assert.EqualValues(t, body, string(fastCtx.Response.Body()), in)
+	}
+
+	fastCtx := serveTest(g, MethodPost, "/y", withHeader(fasthttp.HeaderHost, "api.example.com"))
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "GET", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+}
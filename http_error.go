@@ -0,0 +1,136 @@
+package gorouter
+
+import (
+	"strconv"
+	"strings"
+
+	json "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// HTTPError is the error with the response status. If the handler pipeline ends with it,
+// the router sets the status and renders the body: plain text, JSON or problem+json per "Accept" header.
+type HTTPError struct {
+	Code    int    // response status
+	Message string // message for the client, the status text is used if it's empty
+	Details any    // optional data for the client, it's rendered in JSON responses only
+	Cause   error  // internal cause, it's never sent to the client
+}
+
+func NewHTTPError(code int, message string) *HTTPError {
+	return &HTTPError{Code: code, Message: message}
+}
+
+func BadRequest(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusBadRequest, message)
+}
+
+func Unauthorized(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusUnauthorized, message)
+}
+
+func Forbidden(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusForbidden, message)
+}
+
+func NotFound(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusNotFound, message)
+}
+
+func Conflict(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusConflict, message)
+}
+
+func UnprocessableEntity(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusUnprocessableEntity, message)
+}
+
+func TooManyRequests(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusTooManyRequests, message)
+}
+
+func InternalServerError(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusInternalServerError, message)
+}
+
+func ServiceUnavailable(message string) *HTTPError {
+	return NewHTTPError(fasthttp.StatusServiceUnavailable, message)
+}
+
+// WithDetails sets up the data for the client.
+func (e *HTTPError) WithDetails(details any) *HTTPError {
+	e.Details = details
+	return e
+}
+
+// Wrap sets up the internal cause of the error.
+func (e *HTTPError) Wrap(cause error) *HTTPError {
+	e.Cause = cause
+	return e
+}
+
+func (e *HTTPError) Error() string {
+	out := strconv.Itoa(e.Code) + " " + e.Text()
+	if e.Cause != nil {
+		out += ": " + e.Cause.Error()
+	}
+
+	return out
+}
+
+func (e *HTTPError) Unwrap() error {
+	return e.Cause
+}
+
+// Text returns the message or the status text if the message is empty.
+func (e *HTTPError) Text() string {
+	if e.Message != "" {
+		return e.Message
+	}
+
+	return fasthttp.StatusMessage(e.Code)
+}
+
+// asHTTPError returns the HTTPError from the chain of errors or nil.
+func asHTTPError(err error) *HTTPError {
+	var he *HTTPError
+	if errors.As(err, &he) {
+		return he
+	}
+
+	return nil
+}
+
+const (
+	contentTypeJSON    = "application/json"
+	contentTypeProblem = "application/problem+json"
+	contentTypeText    = "text/plain; charset=utf-8"
+)
+
+// Render writes the error to the response, the format depends on the "Accept" header of the request.
+func (e *HTTPError) Render(ctx *Context) {
+	fastCtx := ctx.FastCtx()
+	fastCtx.SetStatusCode(e.Code)
+
+	accept := string(fastCtx.Request.Header.Peek(fasthttp.HeaderAccept))
+	switch {
+	case strings.Contains(accept, contentTypeProblem):
+		ProblemOf(ctx, e).Render(ctx)
+		return
+	case strings.Contains(accept, contentTypeJSON):
+		body, err := json.ConfigCompatibleWithStandardLibrary.Marshal(map[string]any{
+			"code":    e.Code,
+			"message": e.Text(),
+			"details": e.Details,
+		})
+		if err == nil {
+			fastCtx.SetContentType(contentTypeJSON)
+			fastCtx.SetBody(body)
+			return
+		}
+	}
+
+	fastCtx.SetContentType(contentTypeText)
+	fastCtx.SetBodyString(e.Text())
+}
@@ -0,0 +1,62 @@
+package gorouter
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestHTTPError(t *testing.T) {
+	cause := errors.New("no rows")
+	err := NotFound("user not found").WithDetails(map[string]any{"id": 15}).Wrap(cause)
+
+	assert.EqualValues(t, fasthttp.StatusNotFound, err.Code)
+	assert.EqualError(t, err, "404 user not found: no rows")
+	assert.True(t, errors.Is(err, cause))
+	assert.EqualValues(t, "Bad Request", BadRequest("").Text())
+
+	wrapped := errors.Join(errors.New("other"), err)
+	assert.Equal(t, err, asHTTPError(wrapped))
+	assert.Nil(t, asHTTPError(cause))
+}
+
+func TestServerHTTPError(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", &FailHandler{Err: NotFound("user not found").WithDetails(map[string]any{"id": 15})}).
+		Get("/fail", &FailHandler{Err: ServiceUnavailable("").Wrap(errors.New("db is down"))})
+
+	fastCtx := serveTest(g, MethodGet, "/user/15", withHeader(fasthttp.HeaderAccept, "text/html"))
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, contentTypeText, string(fastCtx.Response.Header.ContentType()))
+	assert.EqualValues(t, "user not found", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/user/15", withHeader(fasthttp.HeaderAccept, "application/json, text/plain"))
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, contentTypeJSON, string(fastCtx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"code":404,"message":"user not found","details":{"id":15}}`, string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/user/15", withHeader(fasthttp.HeaderAccept, "application/problem+json"))
+	assert.EqualValues(t, contentTypeProblem, string(fastCtx.Response.Header.ContentType()))
+	assert.JSONEq(t, `{"type":"about:blank","title":"Not Found","status":404,"detail":"user not found",
+		"instance":"/user/15","details":{"id":15}}`, string(fastCtx.Response.Body()))
+
+	// the cause is never sent to the client
+	fastCtx = serveTest(g, MethodGet, "/fail")
+	assert.EqualValues(t, fasthttp.StatusServiceUnavailable, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "Service Unavailable", string(fastCtx.Response.Body()))
+
+	// the error handler may change the rendered response
+	var handled error
+	g.ErrorHandler(func(ctx *Context, err error) {
+		handled = err
+		ctx.FastCtx().SetStatusCode(fasthttp.StatusGone)
+	})
+
+	fastCtx = serveTest(g, MethodGet, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusGone, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "user not found", string(fastCtx.Response.Body()))
+	assert.NotNil(t, asHTTPError(handled))
+}
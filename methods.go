@@ -2,6 +2,7 @@ package gorouter
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/valyala/fasthttp"
 )
@@ -21,3 +22,35 @@ const (
 	MethodGetPost = fasthttp.MethodGet + http.MethodPost
 	MethodGetHead = fasthttp.MethodGet + http.MethodHead
 )
+
+// StandardMethods are methods of RFC 7231 and RFC 5789, Any registers the route for all of them.
+// Any other token is a valid method too: "PROPFIND", "PURGE".
+var StandardMethods = []Method{
+	MethodGet, MethodHead, MethodPost, MethodPut, MethodPatch,
+	MethodDelete, MethodConnect, MethodOptions, MethodTrace,
+}
+
+// ValidMethod checks the method is the RFC 7230 token.
+func ValidMethod(method Method) bool {
+	if method == "" {
+		return false
+	}
+
+	for i := 0; i < len(method); i++ {
+		if !isTokenChar(method[i]) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// isTokenChar checks the "tchar" of RFC 7230.
+func isTokenChar(c byte) bool {
+	switch {
+	case 'a' <= c && c <= 'z', 'A' <= c && c <= 'Z', '0' <= c && c <= '9':
+		return true
+	}
+
+	return strings.IndexByte("!#$%&'*+-.^_`|~", c) >= 0
+}
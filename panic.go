@@ -0,0 +1,60 @@
+package gorouter
+
+import (
+	"fmt"
+	"runtime/debug"
+
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// PanicError is the panic of the handler which is recovered by the router.
+// It goes to the last handler and to the error handler as the usual error, the response status is 500.
+type PanicError struct {
+	Value any    // value of the panic
+	Stack []byte // stack trace of the goroutine where the panic happened
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.Value)
+}
+
+// Unwrap returns the value of the panic if it's the error.
+func (e *PanicError) Unwrap() error {
+	if err, ok := e.Value.(error); ok {
+		return err
+	}
+
+	return nil
+}
+
+// safeRun runs the function and turns its panic into PanicError.
+func safeRun(f func() error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &PanicError{Value: r, Stack: debug.Stack()}
+		}
+	}()
+
+	return f()
+}
+
+// asPanic returns the PanicError from the chain of errors or nil.
+func asPanic(err error) *PanicError {
+	var pe *PanicError
+	if errors.As(err, &pe) {
+		return pe
+	}
+
+	return nil
+}
+
+// onPanic logs the recovered panic and answers 500, the last and error handlers may change the response later.
+func (ctx *Context) onPanic(pe *PanicError) {
+	ctx.Logger().Clone().
+		Error(pe).
+		Add("stack", string(pe.Stack)).
+		Errorf("recovered panic in '%s'", string(ctx.fastCtx.Path()))
+
+	ctx.fastCtx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+}
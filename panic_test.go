@@ -0,0 +1,97 @@
+package gorouter
+
+import (
+	"bytes"
+	"errors"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type PanicHandler struct {
+	RunHandler
+
+	Value any
+	InGo  bool // panics in the errgroup goroutine
+}
+
+func (h *PanicHandler) Run(ctx *Context) error {
+	if h.InGo {
+		ctx.EGGo(func() error { panic(h.Value) })
+		return nil
+	}
+
+	panic(h.Value)
+}
+
+type LastPanicHandler struct {
+	Err error
+}
+
+func (h *LastPanicHandler) Name() string {
+	return "LastPanicHandler"
+}
+
+func (h *LastPanicHandler) Run(_ *Context, err error) error {
+	h.Err = err
+	return err
+}
+
+func TestServerPanic(t *testing.T) {
+	buf := &bytes.Buffer{}
+	last := &LastPanicHandler{}
+
+	g := New().SetLoggerWriter(buf)
+	g.Router().
+		Last(last).
+		Get("/panic", &PanicHandler{Value: "boom"}).
+		Get("/panic/go", &PanicHandler{Value: errors.New("go boom"), InGo: true})
+
+	fastCtx := serveTest(g, MethodGet, "/panic")
+	assert.EqualValues(t, fasthttp.StatusInternalServerError, fastCtx.Response.StatusCode())
+
+	var pe *PanicError
+	assert.True(t, errors.As(last.Err, &pe))
+	assert.EqualValues(t, "boom", pe.Value)
+	assert.EqualValues(t, "panic: boom", pe.Error())
+	assert.Contains(t, string(pe.Stack), "PanicHandler")
+	assert.Contains(t, buf.String(), "recovered panic in '/panic'")
+	assert.Contains(t, buf.String(), `"stack":`)
+
+	// the panic is logged once
+	std := &bytes.Buffer{}
+	log.SetOutput(std)
+	defer log.SetOutput(os.Stderr)
+
+	buf.Reset()
+	serveTest(g, MethodGet, "/panic")
+	assert.EqualValues(t, 1, strings.Count(buf.String(), "recovered panic"))
+	assert.Empty(t, std.String())
+
+	fastCtx = serveTest(g, MethodGet, "/panic/go")
+	assert.EqualValues(t, fasthttp.StatusInternalServerError, fastCtx.Response.StatusCode())
+	assert.True(t, errors.As(last.Err, &pe))
+	assert.EqualError(t, errors.Unwrap(pe), "go boom")
+}
+
+func TestServerPanicErrorHandler(t *testing.T) {
+	g := New().SetLoggerWriter(&bytes.Buffer{})
+	g.Router().Get("/panic", &PanicHandler{Value: "boom"})
+
+	var handled error
+	g.ErrorHandler(func(ctx *Context, err error) {
+		handled = err
+		ctx.FastCtx().Response.SetBodyString("custom")
+	})
+
+	fastCtx := serveTest(g, MethodGet, "/panic")
+	assert.EqualValues(t, fasthttp.StatusInternalServerError, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "custom", string(fastCtx.Response.Body()))
+
+	var pe *PanicError
+	assert.True(t, errors.As(handled, &pe))
+}
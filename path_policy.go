@@ -0,0 +1,103 @@
+package gorouter
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+)
+
+// PathPolicy defines how the router treats requests whose path isn't in the canonical form:
+// the trailing slash differs from the registered route, or the path has "//", "." or ".." segments.
+// Regexp routes always match the path as is.
+type PathPolicy int
+
+const (
+	// PathLenient serves the non-canonical path by the route, it's the default policy.
+	PathLenient PathPolicy = iota
+	// PathStrict answers "not found" for the non-canonical path.
+	PathStrict
+	// PathRedirect redirects to the canonical path: 301 for GET and HEAD, 308 for other methods.
+	PathRedirect
+)
+
+func (server *Server) PathPolicy() PathPolicy {
+	return server.pathPolicy
+}
+
+func (server *Server) SetPathPolicy(policy PathPolicy) *Server {
+	server.pathPolicy = policy
+	return server
+}
+
+func (server *Server) CaseInsensitive() bool {
+	return server.caseInsensitive
+}
+
+// SetCaseInsensitive turns on the case-insensitive matching of tree routes.
+// The request is redirected to the path in the registered casing: "/About" => "/about".
+func (server *Server) SetCaseInsensitive(fold bool) *Server {
+	server.caseInsensitive = fold
+	return server
+}
+
+// canonicalPath returns the path with the trailing slash of the registered route.
+// The path is already cleaned by fasthttp.
+func canonicalPath(path string, res TreeResult) string {
+	if path == "/" || path == "" {
+		return "/"
+	}
+
+	path = strings.TrimRight(path, "/")
+	if res.TrailingSlash {
+		path += "/"
+	}
+
+	return path
+}
+
+// originalPath returns the decoded path of the request as it's sent by the client.
+func originalPath(fastCtx *fasthttp.RequestCtx) string {
+	original := string(fastCtx.URI().PathOriginal())
+	if decoded, err := url.PathUnescape(original); err == nil {
+		return decoded
+	}
+
+	return original
+}
+
+// checkPath applies the path policy to the found route. It returns false if the request is answered already
+// or the route must not be used.
+func (server *Server) checkPath(fastCtx *fasthttp.RequestCtx, path string, res TreeResult) bool {
+	if server.pathPolicy == PathLenient || res.regexp {
+		return true
+	}
+
+	canonical := canonicalPath(path, res)
+	if canonical == originalPath(fastCtx) {
+		return true
+	}
+
+	if server.pathPolicy == PathRedirect {
+		redirectPath(fastCtx, canonical)
+	}
+
+	return false
+}
+
+// redirectPath redirects the request to the path, the query is kept.
+func redirectPath(fastCtx *fasthttp.RequestCtx, path string) {
+	uri := fasthttp.AcquireURI()
+	defer fasthttp.ReleaseURI(uri)
+
+	fastCtx.URI().CopyTo(uri)
+	uri.SetPath(path)
+
+	code := fasthttp.StatusPermanentRedirect
+	if method := Method(fastCtx.Method()); method == MethodGet || method == MethodHead {
+		code = fasthttp.StatusMovedPermanently
+	}
+
+	fastCtx.Response.Header.Set(fasthttp.HeaderLocation, string(uri.RequestURI()))
+	fastCtx.SetStatusCode(code)
+}
@@ -0,0 +1,150 @@
+package gorouter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func newPolicyServer() *Server {
+	g := New()
+	g.Router().
+		Get("/about/", newWH("about")).
+		GetPost("/user/:id", newWH("user")).
+		Get("/Files/*path", newWH("files")).
+		UseReg(MethodGet, regexp.MustCompile(`^/item/\d+/?$`), newWH("item"))
+
+	return g
+}
+
+func TestPathPolicyLenient(t *testing.T) {
+	g := newPolicyServer()
+	assert.EqualValues(t, PathLenient, g.PathPolicy())
+
+	for _, uri := range []string{"/about", "/about/", "/user/15/", "/x/../user/15", "/user//15"} {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), uri)
+	}
+}
+
+func TestPathPolicyStrict(t *testing.T) {
+	g := newPolicyServer().SetPathPolicy(PathStrict)
+
+	data := map[string]int{
+		"/about/":            fasthttp.StatusOK,
+		"/about":             fasthttp.StatusNotFound,
+		"/user/15":           fasthttp.StatusOK,
+		"/user/15/":          fasthttp.StatusNotFound,
+		"/x/../user/15":      fasthttp.StatusNotFound,
+		"/Files/a/b":         fasthttp.StatusOK,
+		"/item/15/":          fasthttp.StatusOK,
+		"/item/15":           fasthttp.StatusOK,
+		"/user/a%20b":        fasthttp.StatusOK,
+		"/user/a%20b?tab=15": fasthttp.StatusOK,
+	}
+
+	for uri, code := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, code, fastCtx.Response.StatusCode(), uri)
+	}
+}
+
+func TestPathPolicyRedirect(t *testing.T) {
+	g := newPolicyServer().SetPathPolicy(PathRedirect)
+
+	data := map[string]string{
+		"/about":                "/about/",
+		"/user/15/?tab=info":    "/user/15?tab=info",
+		"/x/../user/15":         "/user/15",
+		"/user//15":             "/user/15",
+		"/user/a%20b/":          "/user/a%20b",
+		"/x/./../about?from=me": "/about/?from=me",
+	}
+
+	for uri, location := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusMovedPermanently, fastCtx.Response.StatusCode(), uri)
+		assert.EqualValues(t, location, string(fastCtx.Response.Header.Peek(fasthttp.HeaderLocation)), uri)
+	}
+
+	fastCtx := serveTest(g, MethodPost, "/user/15/")
+	assert.EqualValues(t, fasthttp.StatusPermanentRedirect, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "/user/15", string(fastCtx.Response.Header.Peek(fasthttp.HeaderLocation)))
+
+	fastCtx = serveTest(g, MethodGet, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "user", string(fastCtx.Response.Body()))
+}
+
+func TestCaseInsensitive(t *testing.T) {
+	g := newPolicyServer()
+
+	fastCtx := serveTest(g, MethodGet, "/ABOUT/")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	g.SetCaseInsensitive(true)
+
+	data := map[string]string{
+		"/ABOUT/":           "/about/",
+		"/About":            "/about/",
+		"/USER/VasYa?x=1":   "/user/VasYa?x=1",
+		"/files/Docs/A.txt": "/Files/Docs/A.txt",
+	}
+
+	for uri, location := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusMovedPermanently, fastCtx.Response.StatusCode(), uri)
+		assert.EqualValues(t, location, string(fastCtx.Response.Header.Peek(fasthttp.HeaderLocation)), uri)
+	}
+
+	fastCtx = serveTest(g, MethodGet, "/user/VasYa")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+}
+
+func TestTreeFindFold(t *testing.T) {
+	tr := newTree()
+	tr.Add(MethodGet, "/User/:id/Profile", Set("1"))
+	tr.Add(MethodGet, "/user/new", Set("2"))
+	tr.Add(MethodGet, "/Files", Set("3"))
+	tr.Add(MethodGet, "/Files/*path", Set("4"))
+
+	args := &fasthttp.Args{}
+	data := map[string][2]string{
+		"/user/Vasya/profile": {"1", "/User/Vasya/Profile"},
+		"/USER/NEW":           {"2", "/user/new"},
+		"/files":              {"3", "/Files"},
+		"/files/A/b":          {"4", "/Files/A/b"},
+	}
+
+	for path, expected := range data {
+		res, registered := tr.FindFold(MethodGet, path, args)
+		assert.True(t, res.Find, path)
+		assert.EqualValues(t, expected[0], res.HandlerSet.ID, path)
+		assert.EqualValues(t, expected[1], registered, path)
+	}
+
+	res, _ := tr.FindFold(MethodGet, "/unknown", args)
+	assert.False(t, res.Find)
+}
+
+func TestPathPolicyStrictSplit(t *testing.T) {
+	// "/abc" splits the node of "/about/", the trailing slash goes with the tail
+	g := New().SetPathPolicy(PathStrict)
+	g.Router().
+		Get("/about/", newWH("about")).
+		Get("/abc", newWH("abc"))
+
+	data := map[string]int{
+		"/about/": fasthttp.StatusOK,
+		"/about":  fasthttp.StatusNotFound,
+		"/abc":    fasthttp.StatusOK,
+		"/abc/":   fasthttp.StatusNotFound,
+	}
+
+	for uri, code := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, code, fastCtx.Response.StatusCode(), uri)
+	}
+}
@@ -0,0 +1,147 @@
+package gorouter
+
+import (
+	json "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+	"github.com/valyala/fasthttp"
+)
+
+// Problem is the RFC 7807 problem document. The handler may return it as the error,
+// extension members are rendered on the top level of the document.
+type Problem struct {
+	Type       string         // URI of the problem type, "about:blank" by default
+	Title      string         // short summary, the status text by default
+	Status     int            // response status, 500 by default
+	Detail     string         // explanation of this occurrence of the problem
+	Instance   string         // URI of this occurrence, the request path by default
+	Extensions map[string]any // additional members
+}
+
+func NewProblem(status int, detail string) *Problem {
+	return &Problem{Status: status, Detail: detail}
+}
+
+// With adds the extension member.
+func (p *Problem) With(key string, value any) *Problem {
+	if p.Extensions == nil {
+		p.Extensions = map[string]any{}
+	}
+
+	p.Extensions[key] = value
+	return p
+}
+
+func (p *Problem) Error() string {
+	if p.Detail != "" {
+		return p.title() + ": " + p.Detail
+	}
+
+	return p.title()
+}
+
+func (p *Problem) status() int {
+	if p.Status == 0 {
+		return fasthttp.StatusInternalServerError
+	}
+
+	return p.Status
+}
+
+func (p *Problem) title() string {
+	if p.Title != "" {
+		return p.Title
+	}
+
+	return fasthttp.StatusMessage(p.status())
+}
+
+func (p *Problem) MarshalJSON() ([]byte, error) {
+	out := make(map[string]any, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		out[k] = v
+	}
+
+	out["type"] = p.Type
+	if p.Type == "" {
+		out["type"] = "about:blank"
+	}
+
+	out["title"] = p.title()
+	out["status"] = p.status()
+
+	if p.Detail != "" {
+		out["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		out["instance"] = p.Instance
+	}
+
+	return json.ConfigCompatibleWithStandardLibrary.Marshal(out)
+}
+
+// Render writes the problem document to the response.
+func (p *Problem) Render(ctx *Context) {
+	body, err := json.ConfigCompatibleWithStandardLibrary.Marshal(p)
+	if err != nil {
+		ctx.FastCtx().Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+		return
+	}
+
+	ctx.FastCtx().SetStatusCode(p.status())
+	ctx.FastCtx().SetContentType(contentTypeProblem)
+	ctx.FastCtx().SetBody(body)
+}
+
+// ProblemOf converts the error into the problem document for the request.
+// Problem and HTTPError keep their data, other errors become 500 without details, so internals aren't leaked.
+func ProblemOf(ctx *Context, err error) *Problem {
+	out := &Problem{}
+
+	var problem *Problem
+	switch he := asHTTPError(err); {
+	case errors.As(err, &problem):
+		*out = *problem
+	case he != nil:
+		out.Status = he.Code
+		out.Detail = he.Message // the title is the status text already
+		if he.Details != nil {
+			out.With("details", he.Details)
+		}
+	default:
+		out.Status = fasthttp.StatusInternalServerError
+	}
+
+	if out.Instance == "" {
+		out.Instance = string(ctx.FastCtx().Path())
+	}
+
+	return out
+}
+
+// ProblemHandler is the last handler which renders errors as RFC 7807 documents.
+// Server errors (5xx) are logged by the logger of the request, recovered panics are logged already.
+type ProblemHandler struct{}
+
+func NewProblemHandler() *ProblemHandler {
+	return &ProblemHandler{}
+}
+
+func (h *ProblemHandler) Name() string {
+	return "ProblemHandler"
+}
+
+func (h *ProblemHandler) Run(ctx *Context, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	problem := ProblemOf(ctx, err)
+	problem.Render(ctx)
+
+	if problem.status() >= fasthttp.StatusInternalServerError && asPanic(err) == nil {
+		ctx.Logger().Clone().Error(err).Errorf("problem '%s' in '%s'", problem.title(), problem.Instance)
+	}
+
+	return nil
+}
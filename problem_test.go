@@ -0,0 +1,64 @@
+package gorouter
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestProblemJSON(t *testing.T) {
+	p := NewProblem(fasthttp.StatusForbidden, "no credit").With("balance", 30)
+	p.Type = "https://example.com/probs/out-of-credit"
+
+	body, err := p.MarshalJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"https://example.com/probs/out-of-credit","title":"Forbidden","status":403,
+		"detail":"no credit","balance":30}`, string(body))
+	assert.EqualError(t, p, "Forbidden: no credit")
+
+	body, err = (&Problem{}).MarshalJSON()
+	assert.Nil(t, err)
+	assert.JSONEq(t, `{"type":"about:blank","title":"Internal Server Error","status":500}`, string(body))
+}
+
+func TestServerProblemHandler(t *testing.T) {
+	buf := &bytes.Buffer{}
+
+	g := New().SetLoggerWriter(buf).Last(NewProblemHandler())
+	g.Router().
+		Get("/problem", &FailHandler{Err: NewProblem(fasthttp.StatusConflict, "version mismatch").With("version", 2)}).
+		Get("/http", &FailHandler{Err: BadRequest("bad id").WithDetails([]string{"id"})}).
+		Get("/internal", &FailHandler{Err: errors.New("secret db error")}).
+		Get("/forbidden", &FailHandler{Err: Forbidden("")}).
+		Get("/ok", newWH("ok"))
+
+	g.Router().Last(&LastPanicHandler{}).Get("/own", &FailHandler{Err: BadRequest("own")})
+
+	data := map[string]string{
+		"/problem":   `{"type":"about:blank","title":"Conflict","status":409,"detail":"version mismatch","instance":"/problem","version":2}`,
+		"/http":      `{"type":"about:blank","title":"Bad Request","status":400,"detail":"bad id","instance":"/http","details":["id"]}`,
+		"/internal":  `{"type":"about:blank","title":"Internal Server Error","status":500,"instance":"/internal"}`,
+		"/forbidden": `{"type":"about:blank","title":"Forbidden","status":403,"instance":"/forbidden"}`,
+	}
+
+	for uri, expected := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, contentTypeProblem, string(fastCtx.Response.Header.ContentType()), uri)
+		assert.JSONEq(t, expected, string(fastCtx.Response.Body()), uri)
+	}
+
+	assert.Contains(t, buf.String(), "secret db error")
+	assert.NotContains(t, buf.String(), "bad id")
+
+	fastCtx := serveTest(g, MethodGet, "/ok")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "ok", string(fastCtx.Response.Body()))
+
+	// the own last handler of the route has priority over the default one
+	fastCtx = serveTest(g, MethodGet, "/own")
+	assert.EqualValues(t, fasthttp.StatusBadRequest, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "own", string(fastCtx.Response.Body()))
+}
@@ -2,55 +2,130 @@ package gorouter
 
 import (
 	"regexp"
+	"strconv"
 
 	"github.com/valyala/fasthttp"
 )
 
+// RegRoute is a single regexp route. Routes with the higher Priority are checked first,
+// routes with the same Priority are checked in registration order.
+type RegRoute struct {
+	Reg        *regexp.Regexp
+	Priority   int
+	HandlerSet *HandlerSet
+}
+
 type RegTree struct {
-	Handlers map[Method]map[*regexp.Regexp]*HandlerSet
+	Handlers map[Method][]*RegRoute
 }
 
 func newRegTree() *RegTree {
 	return &RegTree{
-		Handlers: map[Method]map[*regexp.Regexp]*HandlerSet{
-			MethodGet:     {},
-			MethodHead:    {},
-			MethodPost:    {},
-			MethodPut:     {},
-			MethodPatch:   {},
-			MethodDelete:  {},
-			MethodConnect: {},
-			MethodOptions: {},
-			MethodTrace:   {},
-		},
+		Handlers: map[Method][]*RegRoute{},
 	}
 }
 
 func (rt *RegTree) Add(method Method, path *regexp.Regexp, set *HandlerSet) {
+	rt.AddPriority(method, path, 0, set)
+}
+
+// AddPriority adds the regexp route with the priority. The same regexp replaces the old handler set.
+func (rt *RegTree) AddPriority(method Method, path *regexp.Regexp, priority int, set *HandlerSet) {
 	switch method {
 	case MethodGetPost:
-		rt.Handlers[MethodGet][path] = set
-		rt.Handlers[MethodPost][path] = set
+		rt.add(MethodGet, path, priority, set)
+		rt.add(MethodPost, path, priority, set)
 	case MethodGetHead:
-		rt.Handlers[MethodGet][path] = set
-		rt.Handlers[MethodHead][path] = set
+		rt.add(MethodGet, path, priority, set)
+		rt.add(MethodHead, path, priority, set)
 	default:
-		rt.Handlers[method][path] = set
+		rt.add(method, path, priority, set)
+	}
+}
+
+func (rt *RegTree) add(method Method, path *regexp.Regexp, priority int, set *HandlerSet) {
+	routes := rt.Handlers[method]
+	for i := range routes {
+		if routes[i].Reg.String() == path.String() {
+			routes = append(routes[:i], routes[i+1:]...)
+			break
+		}
 	}
+
+	// after all routes with the same or higher priority
+	pos := len(routes)
+	for i := range routes {
+		if routes[i].Priority < priority {
+			pos = i
+			break
+		}
+	}
+
+	route := &RegRoute{Reg: path, Priority: priority, HandlerSet: set}
+	routes = append(routes, nil)
+	copy(routes[pos+1:], routes[pos:])
+	routes[pos] = route
+
+	rt.Handlers[method] = routes
 }
 
 func (rt *RegTree) Find(method Method, path string, args *fasthttp.Args) TreeResult {
 	args.Reset()
-	if res, find := rt.Handlers[method]; find {
-		for rg, handler := range res {
-			if rg.MatchString(path) {
-				return TreeResult{
-					HandlerSet: handler,
-					Find:       true,
-				}
+	for _, route := range rt.Handlers[method] {
+		match := route.Reg.FindStringSubmatchIndex(path)
+		if match == nil {
+			continue
+		}
+
+		// named groups are url params, optional groups which don't take a part in the match are skipped
+		for i, name := range route.Reg.SubexpNames() {
+			if name != "" && match[2*i] >= 0 {
+				args.Add(name, path[match[2*i]:match[2*i+1]])
 			}
 		}
+
+		return TreeResult{
+			HandlerSet: route.HandlerSet,
+			Find:       true,
+			regexp:     true,
+		}
 	}
 
 	return TreeResult{}
 }
+
+// Methods returns the sorted list of methods which have a regexp matching the path.
+func (rt *RegTree) Methods(path string) []Method {
+	found := map[Method]bool{}
+	for method, routes := range rt.Handlers {
+		for _, route := range routes {
+			if route.HandlerSet != nil && route.Reg.MatchString(path) {
+				found[method] = true
+				break
+			}
+		}
+	}
+
+	return sortMethods(found)
+}
+
+func (rt *RegTree) String() string {
+	out := ""
+	for _, method := range sortMethods(rt.methods()) {
+		for _, route := range rt.Handlers[method] {
+			out += "Method: " + string(method) + ", Reg: " + route.Reg.String() +
+				", Priority: " + strconv.Itoa(route.Priority) + " => [" + route.HandlerSet.ID + "]\n"
+		}
+	}
+
+	return out
+}
+
+func (rt *RegTree) methods() map[Method]bool {
+	out := map[Method]bool{}
+	for method := range rt.Handlers {
+		out[method] = true
+	}
+
+	return out
+}
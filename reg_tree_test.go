@@ -0,0 +1,96 @@
+package gorouter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRegTreeOrder(t *testing.T) {
+	rt := newRegTree()
+	assert.NotNil(t, rt)
+
+	rt.Add(MethodGet, regexp.MustCompile(`^/a/.*`), Set("1"))
+	rt.Add(MethodGet, regexp.MustCompile(`^/a/b.*`), Set("2"))
+	rt.Add(MethodGet, regexp.MustCompile(`^/a/b/c$`), Set("3"))
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	// the map based tree returns the random result here, so check it several times
+	for i := 0; i < 20; i++ {
+		res := rt.Find(MethodGet, "/a/b/c", args)
+		assert.EqualValues(t, true, res.Find, "expected true for '/a/b/c'")
+		assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/a/b/c'")
+	}
+
+	rt.AddPriority(MethodGet, regexp.MustCompile(`^/a/b/c$`), 10, Set("4"))
+	rt.AddPriority(MethodGet, regexp.MustCompile(`^/a/b.*`), 5, Set("5"))
+
+	t.Logf("\n---------\nTestRegTreeOrder:\n%s\n---------\n", rt.String())
+
+	res := rt.Find(MethodGet, "/a/b/c", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/a/b/c'")
+	assert.EqualValues(t, "4", res.HandlerSet.ID, "expected 4 for '/a/b/c'")
+
+	res = rt.Find(MethodGet, "/a/b/d", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/a/b/d'")
+	assert.EqualValues(t, "5", res.HandlerSet.ID, "expected 5 for '/a/b/d'")
+
+	res = rt.Find(MethodGet, "/a/d", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/a/d'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/a/d'")
+
+	assert.Len(t, rt.Handlers[MethodGet], 3, "the same regexp replaces the old route")
+
+	res = rt.Find(MethodPost, "/a/d", args)
+	assert.EqualValues(t, false, res.Find, "expected false for POST '/a/d'")
+}
+
+func TestRegTreeNamedGroups(t *testing.T) {
+	rt := newRegTree()
+	assert.NotNil(t, rt)
+
+	rt.Add(MethodGetPost, regexp.MustCompile(`^/user/(?P<id>\d+)/(?P<action>[a-z]+)$`), Set("1"))
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	res := rt.Find(MethodPost, "/user/15/edit", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/user/15/edit'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/user/15/edit'")
+	assert.EqualValues(t, "15", string(args.Peek("id")), "expected res.UrlIDs for '/user/15/edit'")
+	assert.EqualValues(t, "edit", string(args.Peek("action")), "expected res.UrlIDs for '/user/15/edit'")
+}
+
+func TestRegTreeOptionalGroups(t *testing.T) {
+	rt := newRegTree()
+	assert.NotNil(t, rt)
+
+	rt.Add(MethodGet, regexp.MustCompile(`^/page(/(?P<num>\d+))?$`), Set("1"))
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	res := rt.Find(MethodGet, "/page/3", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/page/3'")
+	assert.EqualValues(t, "3", string(args.Peek("num")), "expected res.UrlIDs for '/page/3'")
+
+	res = rt.Find(MethodGet, "/page", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/page'")
+	assert.EqualValues(t, false, args.Has("num"), "expected no res.UrlIDs for '/page'")
+}
+
+func TestRegTreeExtensionMethods(t *testing.T) {
+	rt := newRegTree()
+	rt.Add("PROPFIND", regexp.MustCompile(`^/dav/`), Set("1"))
+
+	args := &fasthttp.Args{}
+	res := rt.Find("PROPFIND", "/dav/a", args)
+	assert.True(t, res.Find)
+	assert.EqualValues(t, "1", res.HandlerSet.ID)
+	assert.False(t, rt.Find("PURGE", "/dav/a", args).Find)
+	assert.EqualValues(t, []Method{"PROPFIND"}, rt.Methods("/dav/a"))
+}
@@ -0,0 +1,334 @@
+package gorouter
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+var (
+	RouteSyntaxError   = errors.New("route syntax error")
+	RouteConflictError = errors.New("route conflict")
+)
+
+type segmentKind int
+
+const (
+	segmentStatic segmentKind = iota
+	segmentParam
+	segmentCatchAll
+)
+
+// segment is a parsed part of the tree route between slashes.
+type segment struct {
+	kind       segmentKind
+	name       string // static path or param name
+	constraint string // optional constraint of the param
+}
+
+// splitRoute splits the tree route by slashes, slashes inside param constraints don't split it.
+func splitRoute(path string) []string {
+	path = strings.Trim(strings.TrimSpace(path), "/")
+	out := make([]string, 0, strings.Count(path, "/")+1)
+
+	for path != "" {
+		end := strings.IndexByte(path, '/')
+		if path[0] == ':' {
+			end = paramEnd(path)
+		}
+
+		if end < 0 || end == len(path) {
+			out = append(out, path)
+			break
+		}
+
+		out = append(out, path[:end])
+		path = path[end+1:]
+	}
+
+	return out
+}
+
+// parseRoute splits the tree route into segments and checks its syntax.
+func parseRoute(path string) ([]segment, error) {
+	paths := splitRoute(path)
+	out := make([]segment, 0, len(paths))
+
+	for i, p := range paths {
+		switch {
+		case strings.HasPrefix(p, ":"):
+			name, constraint, err := splitParam(p[1:])
+			if err != nil {
+				return nil, fmt.Errorf("%w in '%s'", err, path)
+			}
+			if name == "" {
+				return nil, fmt.Errorf("%w: empty param name in '%s'", RouteSyntaxError, path)
+			}
+			if constraint != "" {
+				if _, err := parseConstraint(constraint); err != nil {
+					return nil, fmt.Errorf("%w in '%s'", err, path)
+				}
+			}
+			out = append(out, segment{kind: segmentParam, name: name, constraint: constraint})
+		case strings.HasPrefix(p, "*"):
+			if len(p) == 1 {
+				return nil, fmt.Errorf("%w: empty catch-all param name in '%s'", RouteSyntaxError, path)
+			}
+			if i != len(paths)-1 {
+				return nil, fmt.Errorf("%w: catch-all segment '%s' must be the last one in '%s'", RouteSyntaxError, p, path)
+			}
+			out = append(out, segment{kind: segmentCatchAll, name: p[1:]})
+		default:
+			out = append(out, segment{kind: segmentStatic, name: p})
+		}
+	}
+
+	return out, nil
+}
+
+// routeMethods expands the composite methods.
+func routeMethods(method Method) []Method {
+	switch method {
+	case MethodGetPost:
+		return []Method{MethodGet, MethodPost}
+	case MethodGetHead:
+		return []Method{MethodGet, MethodHead}
+	}
+
+	return []Method{method}
+}
+
+func methodsOverlap(a, b Method) bool {
+	for _, ma := range routeMethods(a) {
+		for _, mb := range routeMethods(b) {
+			if ma == mb {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// parse returns segments of the tree route, they are parsed once when the route is added to the builder.
+func (route *Route) parse() ([]segment, error) {
+	if route.segments != nil {
+		return route.segments, nil
+	}
+
+	return parseRoute(route.Path)
+}
+
+func (route *Route) String() string {
+	host := ""
+	if route.Host != "" {
+		host = " " + route.Host
+	}
+
+	if route.Reg != nil {
+		return string(route.Method) + host + " " + route.Reg.String()
+	}
+
+	return string(route.Method) + host + " " + route.Path
+}
+
+// routeIndex groups routes which may conflict, so the new route is checked against its group only.
+// Tree routes conflict only if they have the same host and the same first segment,
+// regexp routes conflict only if they have the same host and the same method.
+type routeIndex map[string][]*Route
+
+// routeKeys returns the groups of the route, the route with the syntax error is in no group.
+func routeKeys(route *Route) []string {
+	host := ""
+	if route.Host != "" {
+		host = normalizeHost(route.Host)
+	}
+
+	if route.Reg != nil {
+		methods := routeMethods(route.Method)
+		out := make([]string, 0, len(methods))
+		for _, method := range methods {
+			out = append(out, host+" reg "+string(method))
+		}
+		return out
+	}
+
+	segments, err := route.parse()
+	if err != nil {
+		return nil
+	}
+
+	if len(segments) == 0 {
+		return []string{host + " /"}
+	}
+
+	switch s := segments[0]; s.kind {
+	case segmentStatic:
+		return []string{host + " /" + s.name}
+	case segmentParam:
+		return []string{host + " :<" + s.constraint + ">"}
+	}
+
+	return []string{host + " *"}
+}
+
+func newRouteIndex(routes ...*Route) routeIndex {
+	index := routeIndex{}
+	for _, route := range routes {
+		index.add(route)
+	}
+
+	return index
+}
+
+func (index routeIndex) add(route *Route) {
+	for _, key := range routeKeys(route) {
+		index[key] = append(index[key], route)
+	}
+}
+
+func (index routeIndex) remove(route *Route) {
+	for _, key := range routeKeys(route) {
+		list := index[key]
+		for i := range list {
+			if list[i] == route {
+				index[key] = append(list[:i:i], list[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+// routes returns routes of the groups of the route.
+func (index routeIndex) routes(route *Route) []*Route {
+	keys := routeKeys(route)
+	if len(keys) == 1 {
+		return index[keys[0]]
+	}
+
+	// the regexp route of the composite method is in some groups
+	seen := map[*Route]bool{}
+	var out []*Route
+	for _, key := range keys {
+		for _, old := range index[key] {
+			if !seen[old] {
+				seen[old] = true
+				out = append(out, old)
+			}
+		}
+	}
+
+	return out
+}
+
+// checkRoute checks the new route against the already registered routes.
+func checkRoute(index routeIndex, route *Route) error {
+	if !ValidMethod(route.Method) {
+		return fmt.Errorf("%w: method '%s' is not a token", RouteSyntaxError, route.Method)
+	}
+
+	if route.Host != "" {
+		if _, err := parseHost(route.Host); err != nil {
+			return err
+		}
+	}
+
+	if route.Reg != nil {
+		return checkRegRoute(index.routes(route), route)
+	}
+
+	segments, err := route.parse()
+	if err != nil {
+		return err
+	}
+
+	for _, old := range index.routes(route) {
+		oldSegments, err := old.parse()
+		if err != nil {
+			continue
+		}
+
+		if err := checkTreeRoutes(old, oldSegments, route, segments); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkTreeRoutes walks both routes while they share the same nodes of the tree.
+func checkTreeRoutes(old *Route, oldSegments []segment, route *Route, segments []segment) error {
+	for i := 0; i < len(segments) && i < len(oldSegments); i++ {
+		a, b := segments[i], oldSegments[i]
+		if a.kind != b.kind {
+			return nil
+		}
+
+		switch a.kind {
+		case segmentStatic:
+			if a.name != b.name {
+				return nil
+			}
+		case segmentParam:
+			// params with other constraints are other nodes of the tree
+			if a.constraint != b.constraint {
+				return nil
+			}
+
+			if a.name != b.name {
+				return fmt.Errorf("%w: param '%s' in '%s' is ambiguous with param '%s' in '%s'",
+					RouteConflictError, a.name, route, b.name, old)
+			}
+		default:
+			if a.name != b.name {
+				return fmt.Errorf("%w: param '%s' in '%s' is ambiguous with param '%s' in '%s'",
+					RouteConflictError, a.name, route, b.name, old)
+			}
+		}
+	}
+
+	if len(segments) == len(oldSegments) && methodsOverlap(route.Method, old.Method) {
+		return fmt.Errorf("%w: '%s' is already registered as '%s'", RouteConflictError, route, old)
+	}
+
+	return nil
+}
+
+// checkRegRoute finds the duplicated regexp and the simple case of shadowed ones:
+// the anchored regexp of the single literal string which is matched by the previous regexp.
+func checkRegRoute(routes []*Route, route *Route) error {
+	literal, complete := route.Reg.LiteralPrefix()
+	expr := route.Reg.String()
+	complete = complete && strings.HasPrefix(expr, "^") && strings.HasSuffix(expr, "$")
+
+	for _, old := range routes {
+		if old.Reg == nil || !methodsOverlap(route.Method, old.Method) {
+			continue
+		}
+
+		if old.Reg.String() == route.Reg.String() {
+			return fmt.Errorf("%w: '%s' is already registered", RouteConflictError, route)
+		}
+
+		if complete && old.Priority >= route.Priority && old.Reg.MatchString(literal) {
+			return fmt.Errorf("%w: '%s' is shadowed by '%s'", RouteConflictError, route, old)
+		}
+	}
+
+	return nil
+}
+
+// Validate checks all routes of the builder and returns all found errors.
+func (b *RouteBuilder) Validate() error {
+	index := routeIndex{}
+
+	var out []error
+	for _, route := range b.Routes() {
+		if err := checkRoute(index, route); err != nil {
+			out = append(out, err)
+		}
+		index.add(route)
+	}
+
+	return errors.Join(out...)
+}
@@ -0,0 +1,133 @@
+package gorouter
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCheckRoute(t *testing.T) {
+	routes := []*Route{
+		{Method: MethodGet, Path: "/user/:id"},
+		{Method: MethodGetPost, Path: "/user/:id/profile"},
+		{Method: MethodGet, Path: "/files/*path"},
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/item/\d+$`)},
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/page/.*$`)},
+	}
+
+	data := map[*Route]error{
+		{Method: MethodPost, Path: "/user/:id"}:                                    nil,
+		{Method: MethodGet, Path: "/user/:id/"}:                                    RouteConflictError,
+		{Method: MethodGetHead, Path: "/user/:id"}:                                 RouteConflictError,
+		{Method: MethodPost, Path: "/user/:id/profile"}:                            RouteConflictError,
+		{Method: MethodPut, Path: "/user/:id/profile"}:                             nil,
+		{Method: MethodGet, Path: "/user/:name/x"}:                                 RouteConflictError,
+		{Method: MethodGet, Path: "/user/new"}:                                     nil,
+		{Method: MethodGet, Path: "/files/*name"}:                                  RouteConflictError,
+		{Method: MethodGet, Path: "/files/:name"}:                                  nil,
+		{Method: MethodGet, Path: "/files/*path/x"}:                                RouteSyntaxError,
+		{Method: MethodGet, Path: "/user/:"}:                                       RouteSyntaxError,
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/item/\d+$`)}:                RouteConflictError,
+		{Method: MethodPost, Reg: regexp.MustCompile(`^/item/\d+$`)}:               nil,
+		{Method: MethodGetPost, Reg: regexp.MustCompile(`^/item/\d+$`)}:            RouteConflictError,
+		{Method: MethodGet, Host: "api.example.com", Path: "/user/:id"}:            nil,
+		{Method: MethodGet, Path: "/:name"}:                                        nil,
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/page/about$`)}:              RouteConflictError,
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/page/about`)}:               nil,
+		{Method: MethodGet, Reg: regexp.MustCompile(`^/page/about$`), Priority: 1}: nil,
+	}
+
+	for route, expected := range data {
+		err := checkRoute(newRouteIndex(routes...), route)
+		if expected == nil {
+			assert.Nil(t, err, route.String())
+		} else {
+			assert.True(t, errors.Is(err, expected), route.String()+": "+expected.Error())
+		}
+	}
+}
+
+func TestServerStrictRoutes(t *testing.T) {
+	g := New()
+	g.Router().Get("/user/:id", newWH("1"))
+
+	// conflicts are collected in the default mode
+	g.Router().Get("/user/:id", newWH("2"))
+	g.Router().Get("/user/:name/x", newWH("3"))
+	assert.Len(t, g.RouteErrors(), 2)
+	assert.NotNil(t, g.RouteBuilder().Validate())
+
+	g.SetStrictRoutes(true)
+	assert.Panics(t, func() { g.Router().Get("/user/:id", newWH("4")) })
+	assert.NotPanics(t, func() { g.Router().Get("/item/:id", newWH("5")) })
+
+	// the syntax error panics in any mode
+	g.SetStrictRoutes(false)
+	assert.Panics(t, func() { g.Router().Get("/files/*path/x", newWH("6")) })
+
+	g.SetStrictRoutes(true)
+	assert.NotNil(t, g.Reload(NewRouteBuilder().Add(MethodGet, "/a", Set("1")).Add(MethodGet, "/a", Set("2"))))
+	assert.Nil(t, g.Reload(NewRouteBuilder().Add(MethodGet, "/a", Set("1"))))
+}
+
+func TestParseConstraint(t *testing.T) {
+	data := map[string]map[string]bool{
+		"int":          {"15": true, "-15": true, "+1": true, "": false, "-": false, "1.5": false},
+		"uint":         {"15": true, "-15": false, "a": false},
+		"alpha":        {"abc": true, "вася": true, "ab1": false},
+		"uuid":         {"6ba7b810-9dad-11d1-80b4-00c04fd430c8": true, "6ba7b810-9dad-11d1-80b4-00c04fd430c": false},
+		"enum(a, b,c)": {"a": true, "b": true, "c": true, "d": false, "a,b": false},
+		`[a-z-]+`:      {"hello-world": true, "Hello": false, "hello/world": false},
+		`\d{2}|\d{4}`:  {"15": true, "2024": true, "123": false, "15x": false},
+	}
+
+	for expr, values := range data {
+		c, err := parseConstraint(expr)
+		assert.Nil(t, err, expr)
+
+		for value, expected := range values {
+			assert.EqualValues(t, expected, c.match(value), expr+": "+value)
+		}
+	}
+
+	for _, expr := range []string{"", "enum(a,,b)", "[a-z"} {
+		_, err := parseConstraint(expr)
+		assert.True(t, errors.Is(err, RouteSyntaxError), expr)
+	}
+}
+
+func TestCheckRouteConstraints(t *testing.T) {
+	routes := []*Route{
+		{Method: MethodGet, Path: "/item/:id<int>"},
+		{Method: MethodGet, Path: "/item/:name"},
+	}
+
+	data := map[*Route]error{
+		{Method: MethodGet, Path: "/item/:id<uuid>"}:     nil,
+		{Method: MethodGet, Path: "/item/:id<int>"}:      RouteConflictError,
+		{Method: MethodGet, Path: "/item/:num<int>/x"}:   RouteConflictError,
+		{Method: MethodGet, Path: "/item/:id<[a-z]+>/x"}: nil,
+		{Method: MethodGet, Path: "/item/:id<int"}:       RouteSyntaxError,
+		{Method: MethodGet, Path: "/item/:id<[a-z>"}:     RouteSyntaxError,
+		{Method: MethodGet, Path: "/item/:<int>"}:        RouteSyntaxError,
+	}
+
+	for route, expected := range data {
+		err := checkRoute(newRouteIndex(routes...), route)
+		if expected == nil {
+			assert.Nil(t, err, route.String())
+		} else {
+			assert.True(t, errors.Is(err, expected), route.String()+": "+expected.Error())
+		}
+	}
+
+	segments, err := parseRoute("/path/:p<[a-z]+/[a-z]+>/x")
+	assert.Nil(t, err)
+	assert.EqualValues(t, []segment{
+		{kind: segmentStatic, name: "path"},
+		{kind: segmentParam, name: "p", constraint: "[a-z]+/[a-z]+"},
+		{kind: segmentStatic, name: "x"},
+	}, segments)
+}
@@ -0,0 +1,285 @@
+package gorouter
+
+import (
+	"regexp"
+	"sync"
+
+	"github.com/valyala/fasthttp"
+)
+
+// Route is a single registered route. The route table is compiled from the list of routes.
+type Route struct {
+	Method     Method
+	Host       string         // optional host pattern, routes without host are in the fallback table
+	Path       string         // tree route, it's empty for regexp routes
+	Reg        *regexp.Regexp // regexp route
+	Priority   int            // priority of regexp route
+	Name       string         // optional name to build URLs
+	HandlerSet *HandlerSet
+
+	segments []segment
+}
+
+// RouteBuilder collects routes to build the immutable route table.
+type RouteBuilder struct {
+	sync.RWMutex
+
+	routes []*Route
+	names  map[string]*Route
+	index  routeIndex // groups of routes for conflict checks
+}
+
+func NewRouteBuilder() *RouteBuilder {
+	return &RouteBuilder{
+		routes: make([]*Route, 0),
+		names:  map[string]*Route{},
+		index:  routeIndex{},
+	}
+}
+
+func (b *RouteBuilder) add(route *Route) *RouteBuilder {
+	if route.Reg == nil && route.segments == nil {
+		route.segments, _ = parseRoute(route.Path)
+	}
+
+	b.Lock()
+	defer b.Unlock()
+
+	b.routes = append(b.routes, route)
+	b.index.add(route)
+	if route.Name != "" {
+		b.names[route.Name] = route
+	}
+
+	return b
+}
+
+// Name sets up the name of the last added route.
+func (b *RouteBuilder) Name(name string) *RouteBuilder {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.routes) == 0 {
+		panic("no route for the name '" + name + "'")
+	}
+
+	b.setName(b.routes[len(b.routes)-1], name)
+	return b
+}
+
+func (b *RouteBuilder) setName(route *Route, name string) {
+	if route.Name != "" && b.names[route.Name] == route {
+		delete(b.names, route.Name)
+	}
+
+	route.Name = name
+	b.names[name] = route
+}
+
+// Host sets up the host pattern of the last added route.
+func (b *RouteBuilder) Host(host string) *RouteBuilder {
+	b.Lock()
+	defer b.Unlock()
+
+	if len(b.routes) == 0 {
+		panic("no route for the host '" + host + "'")
+	}
+
+	route := b.routes[len(b.routes)-1]
+	b.index.remove(route)
+	route.Host = host
+	b.index.add(route)
+
+	return b
+}
+
+// Route returns the route by the name or nil.
+func (b *RouteBuilder) Route(name string) *Route {
+	b.RLock()
+	defer b.RUnlock()
+
+	return b.names[name]
+}
+
+// Add adds the tree route.
+func (b *RouteBuilder) Add(method Method, path string, set *HandlerSet) *RouteBuilder {
+	return b.add(&Route{Method: method, Path: path, HandlerSet: set})
+}
+
+// AddReg adds the regexp route.
+func (b *RouteBuilder) AddReg(method Method, reg *regexp.Regexp, priority int, set *HandlerSet) *RouteBuilder {
+	return b.add(&Route{Method: method, Reg: reg, Priority: priority, HandlerSet: set})
+}
+
+// Routes returns the copy of the list of routes in registration order.
+func (b *RouteBuilder) Routes() []*Route {
+	b.RLock()
+	defer b.RUnlock()
+
+	return append([]*Route{}, b.routes...)
+}
+
+func (b *RouteBuilder) Clone() *RouteBuilder {
+	b.RLock()
+	defer b.RUnlock()
+
+	out := &RouteBuilder{
+		routes: append([]*Route{}, b.routes...),
+		names:  map[string]*Route{},
+		index:  newRouteIndex(b.routes...),
+	}
+
+	for name, route := range b.names {
+		out.names[name] = route
+	}
+
+	return out
+}
+
+// Build compiles routes into the new route table.
+// Routes with the host pattern are compiled into the separate table of the host.
+// Routes with invalid host patterns are skipped, Validate reports them.
+func (b *RouteBuilder) Build() *RouteTable {
+	table := newRouteTable()
+	hosts := map[string]*hostTable{}
+
+	for _, route := range b.Routes() {
+		target := table
+		if route.Host != "" {
+			host, err := parseHost(route.Host)
+			if err != nil {
+				continue
+			}
+
+			ht, find := hosts[host.pattern]
+			if !find {
+				ht = &hostTable{host: host, table: newRouteTable()}
+				hosts[host.pattern] = ht
+				table.hosts = append(table.hosts, ht)
+			}
+			target = ht.table
+		}
+
+		if route.Reg != nil {
+			target.regTree.AddPriority(route.Method, route.Reg, route.Priority, route.HandlerSet)
+		} else {
+			target.tree.Add(route.Method, route.Path, route.HandlerSet)
+		}
+	}
+
+	sortHosts(table.hosts)
+
+	return table
+}
+
+// RouteTable is the compiled set of routes. It's never changed after Build, so lookups need no locks.
+type RouteTable struct {
+	tree    *Tree
+	regTree *RegTree
+	hosts   []*hostTable
+}
+
+func newRouteTable() *RouteTable {
+	return &RouteTable{
+		tree:    newTree(),
+		regTree: newRegTree(),
+	}
+}
+
+// Find looks for the route in the fallback table (routes without host).
+func (table *RouteTable) Find(method Method, path string, args *fasthttp.Args) TreeResult {
+	if res := table.tree.Find(method, path, args); res.Find {
+		return res
+	}
+
+	if res := table.regTree.Find(method, path, args); res.Find {
+		return res
+	}
+
+	return TreeResult{}
+}
+
+// matchHost normalizes the host if there are tables of hosts, tables of matched patterns are checked
+// in priority order before the fallback table.
+func (table *RouteTable) matchHost(host string) (string, bool) {
+	if len(table.hosts) == 0 || host == "" {
+		return host, false
+	}
+
+	return normalizeHost(host), true
+}
+
+// FindHost looks for the route in tables of all matched host patterns, then in the fallback table (routes without host).
+// Host params are added to args if the route of the host is found.
+func (table *RouteTable) FindHost(host string, method Method, path string, args *fasthttp.Args) TreeResult {
+	if host, ok := table.matchHost(host); ok {
+		for _, ht := range table.hosts {
+			if !ht.host.match(host, nil) {
+				continue
+			}
+
+			if res := ht.table.Find(method, path, args); res.Find {
+				ht.host.match(host, args)
+				return res
+			}
+		}
+	}
+
+	return table.Find(method, path, args)
+}
+
+// FindHostFold is the case-insensitive FindHost for tree routes, see Tree.FindFold.
+func (table *RouteTable) FindHostFold(host string, method Method, path string, args *fasthttp.Args) (TreeResult, string) {
+	if host, ok := table.matchHost(host); ok {
+		for _, ht := range table.hosts {
+			if !ht.host.match(host, nil) {
+				continue
+			}
+
+			if res, registered := ht.table.tree.FindFold(method, path, args); res.Find {
+				ht.host.match(host, args)
+				return res, registered
+			}
+		}
+	}
+
+	return table.tree.FindFold(method, path, args)
+}
+
+// MethodsHost returns the sorted list of methods registered for the path in tables of all matched host patterns
+// and in the fallback table.
+func (table *RouteTable) MethodsHost(host, path string) []Method {
+	host, ok := table.matchHost(host)
+	if !ok {
+		return table.Methods(path)
+	}
+
+	found := map[Method]bool{}
+	for _, m := range table.Methods(path) {
+		found[m] = true
+	}
+
+	for _, ht := range table.hosts {
+		if ht.host.match(host, nil) {
+			for _, m := range ht.table.Methods(path) {
+				found[m] = true
+			}
+		}
+	}
+
+	return sortMethods(found)
+}
+
+// Methods returns the sorted list of methods registered for the path in both trees.
+func (table *RouteTable) Methods(path string) []Method {
+	found := map[Method]bool{}
+	for _, m := range table.tree.Methods(path) {
+		found[m] = true
+	}
+
+	for _, m := range table.regTree.Methods(path) {
+		found[m] = true
+	}
+
+	return sortMethods(found)
+}
@@ -0,0 +1,110 @@
+package gorouter
+
+import (
+	"regexp"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouteBuilder(t *testing.T) {
+	b := NewRouteBuilder().
+		Add(MethodGet, "/user/:id", Set("1")).
+		AddReg(MethodGet, regexp.MustCompile(`^/item/(?P<id>\d+)$`), 0, Set("2"))
+
+	table := b.Build()
+	assert.NotNil(t, table)
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	res := table.Find(MethodGet, "/user/15", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/user/15'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/user/15'")
+
+	res = table.Find(MethodGet, "/item/16", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/item/16'")
+	assert.EqualValues(t, "2", res.HandlerSet.ID, "expected 2 for '/item/16'")
+	assert.EqualValues(t, "16", string(args.Peek("id")), "expected res.UrlIDs for '/item/16'")
+
+	// the table is not changed by the builder
+	b.Add(MethodGet, "/new", Set("3"))
+	res = table.Find(MethodGet, "/new", args)
+	assert.EqualValues(t, false, res.Find, "expected false for '/new'")
+	assert.Len(t, b.Routes(), 3)
+}
+
+func TestServerReload(t *testing.T) {
+	g := New()
+	g.Router().Get("/a", newWH("a"))
+
+	fastCtx := serveTest(g, MethodGet, "/a")
+	assert.EqualValues(t, "a", string(fastCtx.Response.Body()))
+
+	// the feature flagged set of routes
+	next := g.RouteBuilder().Add(MethodGet, "/b", Set("b").Use(newWH("b")))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			args := &fasthttp.Args{}
+			for j := 0; j < 1000; j++ {
+				res := g.Find(MethodGet, "/a", args)
+				assert.EqualValues(t, true, res.Find, "expected true for '/a'")
+			}
+		}()
+	}
+
+	assert.Nil(t, g.Reload(next))
+	wg.Wait()
+
+	fastCtx = serveTest(g, MethodGet, "/b")
+	assert.EqualValues(t, "b", string(fastCtx.Response.Body()))
+
+	assert.Nil(t, g.Reload(NewRouteBuilder()))
+
+	fastCtx = serveTest(g, MethodGet, "/a")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	// routes which are added after Reload go to the new set
+	g.Router().Get("/c", newWH("c"))
+	fastCtx = serveTest(g, MethodGet, "/c")
+	assert.EqualValues(t, "c", string(fastCtx.Response.Body()))
+}
+
+func TestServerPublish(t *testing.T) {
+	g := New()
+	g.Router().Get("/user/:id", newWH("user"))
+
+	// the table is compiled once on the first lookup after registration
+	table := g.RouteTable()
+	assert.True(t, table.Find(MethodGet, "/user/15", &fasthttp.Args{}).Find)
+	assert.Same(t, table, g.RouteTable())
+
+	g.Router().Mount("/admin", NewRouteBuilder().
+		Add(MethodGet, "/a", Set("a").Use(newWH("a"))).
+		Add(MethodGet, "/b", Set("b").Use(newWH("b"))))
+
+	// concurrent lookups get the same new table
+	tables := make([]*RouteTable, 8)
+	wg := sync.WaitGroup{}
+	for i := range tables {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			tables[i] = g.RouteTable()
+		}()
+	}
+	wg.Wait()
+
+	assert.NotSame(t, table, tables[0])
+	for _, tb := range tables {
+		assert.Same(t, tables[0], tb)
+		assert.True(t, tb.Find(MethodGet, "/admin/b", &fasthttp.Args{}).Find)
+	}
+}
@@ -0,0 +1,107 @@
+package gorouter
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+var RouteURLError = errors.New("cannot build url")
+
+// URL builds the path of the route from params, params are key-value pairs: "id", "15", "path", "a/b.txt".
+// Param values are escaped, catch-all values keep their slashes. Missing and unknown params are errors.
+// The trailing slash of the route is kept: "/user/:id/" => "/user/15/".
+func (route *Route) URL(params ...string) (string, error) {
+	if route.Reg != nil {
+		return "", fmt.Errorf("%w: '%s' is the regexp route", RouteURLError, route)
+	}
+
+	if len(params)%2 != 0 {
+		return "", fmt.Errorf("%w: odd number of params for '%s'", RouteURLError, route)
+	}
+
+	values := make(map[string]string, len(params)/2)
+	for i := 0; i < len(params); i += 2 {
+		values[params[i]] = params[i+1]
+	}
+
+	segments, err := route.parse()
+	if err != nil {
+		return "", err
+	}
+
+	out := make([]string, 0, len(segments))
+	for _, s := range segments {
+		if s.kind == segmentStatic {
+			out = append(out, s.name)
+			continue
+		}
+
+		value, find := values[s.name]
+		if !find {
+			return "", fmt.Errorf("%w: missing param '%s' for '%s'", RouteURLError, s.name, route)
+		}
+		delete(values, s.name)
+
+		if s.kind == segmentParam {
+			if value == "" {
+				return "", fmt.Errorf("%w: empty param '%s' for '%s'", RouteURLError, s.name, route)
+			}
+
+			if s.constraint != "" {
+				if c, err := parseConstraint(s.constraint); err != nil || !c.match(value) {
+					return "", fmt.Errorf("%w: param '%s' doesn't match '%s' for '%s'", RouteURLError, s.name, s.constraint, route)
+				}
+			}
+
+			out = append(out, url.PathEscape(value))
+			continue
+		}
+
+		// catch-all param
+		parts := strings.Split(strings.Trim(value, "/"), "/")
+		for i := range parts {
+			parts[i] = url.PathEscape(parts[i])
+		}
+
+		if value := strings.Join(parts, "/"); value != "" {
+			out = append(out, value)
+		}
+	}
+
+	for key := range values {
+		return "", fmt.Errorf("%w: unknown param '%s' for '%s'", RouteURLError, key, route)
+	}
+
+	path := "/" + strings.Join(out, "/")
+
+	// the canonical path of the route keeps its trailing slash, see PathPolicy
+	if path != "/" && trimPath(route.Path) != "" && strings.HasSuffix(strings.TrimSpace(route.Path), "/") {
+		path += "/"
+	}
+
+	return path, nil
+}
+
+// URL builds the path of the named route, see Route.URL.
+func (server *Server) URL(name string, params ...string) (string, error) {
+	server.routesLock.Lock()
+	route := server.routes.Route(name)
+	server.routesLock.Unlock()
+
+	if route == nil {
+		return "", fmt.Errorf("%w: unknown route name '%s'", RouteURLError, name)
+	}
+
+	return route.URL(params...)
+}
+
+// URLFor builds the path of the named route of the current server, see Route.URL.
+func (ctx *Context) URLFor(name string, params ...string) (string, error) {
+	if ctx.server == nil {
+		return "", fmt.Errorf("%w: no server for the route name '%s'", RouteURLError, name)
+	}
+
+	return ctx.server.URL(name, params...)
+}
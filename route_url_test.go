@@ -0,0 +1,103 @@
+package gorouter
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type URLHandler struct {
+	RunHandler
+}
+
+func (h *URLHandler) Run(ctx *Context) error {
+	u, err := ctx.URLFor("user", "id", ctx.PeekStringParam("id"))
+	if err != nil {
+		return err
+	}
+
+	_, err = ctx.WriteString(u)
+	return err
+}
+
+func TestServerURL(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", &URLHandler{}).Name("user").
+		Get("/user/:id/files/*path", newWH("files")).Name("files").
+		Get("/about/", newWH("about")).Name("about").
+		Get("/item/:id<int>", newWH("item")).Name("item-int").
+		UseReg(MethodGet, regexp.MustCompile(`^/item/\d+$`), newWH("item")).Name("item")
+
+	g.Get("/", newWH("index"), Set("")).Name("index")
+
+	data := map[string][]string{
+		"/user/15":                       {"user", "id", "15"},
+		"/user/a%2Fb%20c":                {"user", "id", "a/b c"},
+		"/user/15/files/a/b%20c/d.txt":   {"files", "id", "15", "path", "a/b c/d.txt"},
+		"/user/15/files":                 {"files", "id", "15", "path", ""},
+		"/about/":                        {"about"},
+		"/item/15":                       {"item-int", "id", "15"},
+		"/":                              {"index"},
+		"/user/%D0%B2%D0%B0%D1%81%D1%8F": {"user", "id", "вася"},
+	}
+
+	for expected, params := range data {
+		u, err := g.URL(params[0], params[1:]...)
+		assert.Nil(t, err, expected)
+		assert.EqualValues(t, expected, u)
+	}
+
+	errs := [][]string{
+		{"unknown"},
+		{"user"},
+		{"user", "id"},
+		{"user", "id", ""},
+		{"user", "id", "15", "tab", "1"},
+		{"files", "id", "15"},
+		{"item"},
+		{"item-int", "id", "abc"},
+	}
+
+	for _, params := range errs {
+		_, err := g.URL(params[0], params[1:]...)
+		assert.True(t, errors.Is(err, RouteURLError), params)
+	}
+
+	fastCtx := serveTest(g, MethodGet, "/user/vasya")
+	assert.EqualValues(t, "/user/vasya", string(fastCtx.Response.Body()))
+
+	// the name must be unique
+	g.SetStrictRoutes(true)
+	assert.Panics(t, func() { g.Router().Get("/other", newWH("other")).Name("user") })
+}
+
+func TestServerURLPathPolicy(t *testing.T) {
+	for _, policy := range []PathPolicy{PathLenient, PathStrict, PathRedirect} {
+		g := New().SetPathPolicy(policy)
+		g.Router().
+			Get("/about/", newWH("about")).Name("about").
+			Get("/user/:id/", newWH("user")).Name("user").
+			Get("/user/:id/files/*path", newWH("files")).Name("files")
+
+		data := map[string][]string{
+			"/about/":          {"about"},
+			"/user/15/":        {"user", "id", "15"},
+			"/user/15/files/a": {"files", "id", "15", "path", "a"},
+		}
+
+		for expected, params := range data {
+			u, err := g.URL(params[0], params[1:]...)
+			assert.Nil(t, err, expected)
+			assert.EqualValues(t, expected, u, policy)
+
+			// the built url is served by the route as is
+			fastCtx := serveTest(g, MethodGet, u)
+			assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), u, policy)
+			assert.EqualValues(t, params[0], string(fastCtx.Response.Body()), u, policy)
+		}
+	}
+}
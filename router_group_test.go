@@ -0,0 +1,176 @@
+package gorouter
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestRouterGroup(t *testing.T) {
+	g := New()
+	router := g.Router().Before(&NamedHandler{name: "auth"})
+
+	api := router.Group("/api/")
+	v2 := api.Group("v2").AddBefore(&NamedHandler{name: "v2"})
+	v2.Get("/user/:id", newWH("user")).
+		Get("/", newWH("index")).
+		UseReg(MethodGet, regexp.MustCompile(`^/item/\d+$`), newWH("item"))
+
+	router.Get("/about", newWH("about"))
+
+	assert.EqualValues(t, "/api/v2", v2.Prefix())
+
+	data := map[string]string{
+		"/api/v2/user/15": "user",
+		"/api/v2":         "index",
+		"/api/v2/item/15": "item",
+		"/about":          "about",
+	}
+
+	for uri, body := range data {
+		fastCtx := serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), uri)
+		assert.EqualValues(t, body, string(fastCtx.Response.Body()), uri)
+	}
+
+	fastCtx := serveTest(g, MethodGet, "/item/15")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	// the group extends the chain, the parent keeps its own
+	infos := g.Routes()
+	assert.EqualValues(t, []string{"auth", "v2"}, infos[0].Before)
+	assert.EqualValues(t, []string{"auth"}, infos[3].Before)
+}
+
+func TestRouterMount(t *testing.T) {
+	module := New()
+	module.Router().
+		After(&NamedHandler{name: "module-after"}).
+		Get("/user/:id", newWH("user")).Name("user").
+		UseReg(MethodGet, regexp.MustCompile(`^/item/\d+$`), newWH("item"))
+
+	g := New()
+	g.Router().
+		Before(&NamedHandler{name: "auth"}).
+		After(&NamedHandler{name: "metrics"}).
+		Group("/admin").
+		Mount("/module", module)
+
+	fastCtx := serveTest(g, MethodGet, "/admin/module/user/15")
+	assert.EqualValues(t, "user", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/admin/module/item/15")
+	assert.EqualValues(t, "item", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	infos := g.Routes()
+	assert.Len(t, infos, 2)
+	assert.EqualValues(t, []string{"auth"}, infos[0].Before)
+	assert.EqualValues(t, []string{"module-after", "metrics"}, infos[0].After)
+
+	u, err := g.URL("user", "id", "15")
+	assert.Nil(t, err)
+	assert.EqualValues(t, "/admin/module/user/15", u)
+
+	// the module is not changed
+	fastCtx = serveTest(module, MethodGet, "/user/15")
+	assert.EqualValues(t, "user", string(fastCtx.Response.Body()))
+}
+
+func TestJoinPath(t *testing.T) {
+	data := map[[2]string]string{
+		{"", ""}:             "/",
+		{"/", "/"}:           "/",
+		{"/api", "/"}:        "/api",
+		{"/api/", "/user/"}:  "/api/user/",
+		{"/api/", "user"}:    "/api/user",
+		{"api", "user/:id"}:  "/api/user/:id",
+		{"", "/files/*path"}: "/files/*path",
+		{"/a/b", "c/*path"}:  "/a/b/c/*path",
+	}
+
+	for in, expected := range data {
+		assert.EqualValues(t, expected, joinPath(in[0], in[1]), in)
+	}
+}
+
+func TestRouterMountName(t *testing.T) {
+	module := New()
+	module.Router().Get("/user/:id", newWH("user")).Name("user")
+
+	g := New()
+	g.Router().Get("/user/:id", newWH("user")).Name("user")
+	g.Router().Mount("/module", module)
+
+	errs := g.RouteErrors()
+	assert.Len(t, errs, 1)
+	assert.ErrorIs(t, errs[0], RouteConflictError)
+
+	g = New().SetStrictRoutes(true)
+	g.Router().Mount("/v1", module)
+	assert.Panics(t, func() { g.Router().Mount("/v2", module) })
+}
+
+func TestPrefixReg(t *testing.T) {
+	data := []struct {
+		reg           string
+		match, differ []string
+	}{
+		{`^/item/\d+$`, []string{"/api/item/15"}, []string{"/api/item/15/x", "/item/15", "/api/x/item/15"}},
+		{`(?i)^/item/\d+$`, []string{"/api/item/15", "/api/ITEM/15"}, []string{"/item/15", "/api/item/x"}},
+		{`(?i)/item/\d+$`, []string{"/api/Item/15", "/api/x/item/15"}, []string{"/x/api/item/15"}},
+		{`/item/\d+`, []string{"/api/item/15", "/api/x/item/15/y"}, []string{"/item/15", "/x/api/item/15"}},
+		{`^`, []string{"/api", "/api/x"}, []string{"/x/api"}},
+	}
+
+	for _, d := range data {
+		reg := prefixReg("/api/", regexp.MustCompile(d.reg))
+		for _, path := range d.match {
+			assert.True(t, reg.MatchString(path), d.reg+" "+path)
+		}
+		for _, path := range d.differ {
+			assert.False(t, reg.MatchString(path), d.reg+" "+path)
+		}
+	}
+}
+
+func TestRouterModuleRoutes(t *testing.T) {
+	other := New()
+	other.Router().Get("/b", newWH("b"))
+	module := other.Router()
+	module.Get("/a", newWH("a"))
+
+	g := New()
+	g.Router().Get("/health", newWH("health"))
+	admin := g.Router().Group("/admin")
+	admin.Get("/users", newWH("users"))
+	g.Router().Mount("/m", module)
+
+	// only routes of the router are mounted
+	fastCtx := serveTest(g, MethodGet, "/m/a")
+	assert.EqualValues(t, "a", string(fastCtx.Response.Body()))
+	fastCtx = serveTest(g, MethodGet, "/m/b")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+
+	// the group is the module of its own routes
+	root := g.Router()
+	root.Mount("/self", admin)
+	fastCtx = serveTest(g, MethodGet, "/self/admin/users")
+	assert.EqualValues(t, "users", string(fastCtx.Response.Body()))
+	for _, uri := range []string{"/self/health", "/self/m/a"} {
+		fastCtx = serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode(), uri)
+	}
+
+	// routes of groups belong to the parent router too
+	api := root.Group("/api")
+	api.Group("/v1").Get("/items", newWH("items"))
+	assert.Len(t, api.ModuleRoutes(), 1)
+	assert.Len(t, root.ModuleRoutes(), 2)
+	assert.Len(t, module.ModuleRoutes(), 1)
+	assert.Empty(t, g.RouteErrors())
+}
@@ -0,0 +1,134 @@
+package gorouter
+
+import (
+	"bytes"
+	"strings"
+	"text/tabwriter"
+
+	json "github.com/json-iterator/go"
+)
+
+type RouteKind string
+
+const (
+	RouteKindStatic RouteKind = "static"
+	RouteKindParam  RouteKind = "param"
+	RouteKindRegex  RouteKind = "regex"
+)
+
+// RouteInfo describes the single registered route for introspection and debug goals.
+type RouteInfo struct {
+	Method  Method    `json:"method"`
+	Host    string    `json:"host"`
+	Pattern string    `json:"pattern"`
+	Kind    RouteKind `json:"kind"`
+	ID      string    `json:"id"`
+	Before  []string  `json:"before"`
+	Handler string    `json:"handler"`
+	After   []string  `json:"after"`
+	Last    string    `json:"last"`
+}
+
+type RouteInfos []RouteInfo
+
+func newRouteInfo(method Method, route *Route) RouteInfo {
+	out := RouteInfo{
+		Method:  method,
+		Host:    route.Host,
+		Pattern: route.Path,
+		Kind:    RouteKindStatic,
+		Before:  []string{},
+		After:   []string{},
+	}
+
+	if route.Reg != nil {
+		out.Pattern = route.Reg.String()
+		out.Kind = RouteKindRegex
+	} else if segments, err := route.parse(); err == nil {
+		for _, s := range segments {
+			if s.kind != segmentStatic {
+				out.Kind = RouteKindParam
+			}
+		}
+	}
+
+	if set := route.HandlerSet; set != nil {
+		out.ID = set.ID
+		out.Before = set.BeforeNames()
+		out.Handler = set.HandlerName()
+		out.After = set.AfterNames()
+		out.Last = set.LastName()
+	}
+
+	return out
+}
+
+// Routes returns all registered routes in registration order, composite methods are split.
+func (server *Server) Routes() RouteInfos {
+	out := RouteInfos{}
+	for _, route := range server.RouteBuilder().Routes() {
+		for _, method := range routeMethods(route.Method) {
+			out = append(out, newRouteInfo(method, route))
+		}
+	}
+
+	return out
+}
+
+// String renders routes as the aligned text table.
+func (infos RouteInfos) String() string {
+	buf := &bytes.Buffer{}
+	w := tabwriter.NewWriter(buf, 0, 4, 2, ' ', 0)
+
+	_, _ = w.Write([]byte("METHOD\tHOST\tPATTERN\tKIND\tID\tBEFORE\tHANDLER\tAFTER\tLAST\n"))
+	for _, info := range infos {
+		_, _ = w.Write([]byte(string(info.Method) + "\t" + info.Host + "\t" + info.Pattern + "\t" + string(info.Kind) + "\t" +
+			info.ID + "\t" + strings.Join(info.Before, ",") + "\t" + info.Handler + "\t" +
+			strings.Join(info.After, ",") + "\t" + info.Last + "\n"))
+	}
+
+	_ = w.Flush()
+
+	return buf.String()
+}
+
+// JSON renders routes as the JSON array.
+func (infos RouteInfos) JSON() ([]byte, error) {
+	return json.ConfigCompatibleWithStandardLibrary.Marshal(infos)
+}
+
+// RoutesHandler shows the route table of the server. It answers JSON if the request accepts it
+// or has "format=json" param, otherwise it answers the text table.
+type RoutesHandler struct {
+	RunHandler
+
+	server *Server
+}
+
+func (server *Server) RoutesHandler() *RoutesHandler {
+	return &RoutesHandler{server: server}
+}
+
+func (h *RoutesHandler) Name() string {
+	return "RoutesHandler"
+}
+
+func (h *RoutesHandler) Run(ctx *Context) error {
+	infos := h.server.Routes()
+
+	accept := string(ctx.fastCtx.Request.Header.Peek("Accept"))
+	if ctx.PeekStringParam("format") == "json" || strings.Contains(accept, "application/json") {
+		body, err := infos.JSON()
+		if err != nil {
+			return err
+		}
+
+		ctx.fastCtx.SetContentType("application/json")
+		_, err = ctx.Write(body)
+		return err
+	}
+
+	ctx.fastCtx.SetContentType("text/plain; charset=utf-8")
+	_, err := ctx.WriteString(infos.String())
+	return err
+}
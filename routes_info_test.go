@@ -0,0 +1,67 @@
+package gorouter
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type NamedHandler struct {
+	RunHandler
+
+	name string
+}
+
+func (h *NamedHandler) Name() string {
+	return h.name
+}
+
+func (h *NamedHandler) Run(_ *Context) error {
+	return nil
+}
+
+func TestServerRoutes(t *testing.T) {
+	g := New()
+	g.Router().
+		Before(&NamedHandler{name: "auth"}).
+		After(&NamedHandler{name: "metrics"}).
+		GetPost("/user/:id", &NamedHandler{name: "user"}).
+		Get("/about", &NamedHandler{name: "about"}).
+		UseReg(MethodGet, regexp.MustCompile(`^/item/\d+$`), &NamedHandler{name: "item"})
+
+	g.Get("/admin/routes", g.RoutesHandler(), Set("routes"))
+
+	infos := g.Routes()
+	assert.Len(t, infos, 5)
+
+	assert.EqualValues(t, RouteInfo{
+		Method:  MethodGet,
+		Pattern: "/user/:id",
+		Kind:    RouteKindParam,
+		Before:  []string{"auth"},
+		Handler: "user",
+		After:   []string{"metrics"},
+	}, infos[0])
+	assert.EqualValues(t, MethodPost, infos[1].Method)
+	assert.EqualValues(t, RouteKindStatic, infos[2].Kind)
+	assert.EqualValues(t, RouteKindRegex, infos[3].Kind)
+	assert.EqualValues(t, `^/item/\d+$`, infos[3].Pattern)
+	assert.EqualValues(t, "routes", infos[4].ID)
+
+	text := infos.String()
+	t.Logf("\n%s", text)
+	lines := strings.Split(strings.TrimSpace(text), "\n")
+	assert.Len(t, lines, 6)
+	assert.EqualValues(t, strings.Index(lines[0], "PATTERN"), strings.Index(lines[1], "/user/:id"), "columns are aligned")
+
+	fastCtx := serveTest(g, MethodGet, "/admin/routes?format=json")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "application/json", string(fastCtx.Response.Header.ContentType()))
+	assert.Contains(t, string(fastCtx.Response.Body()), `{"method":"GET","host":"","pattern":"/user/:id","kind":"param","id":"","before":["auth"],"handler":"user","after":["metrics"],"last":""}`)
+
+	fastCtx = serveTest(g, MethodGet, "/admin/routes")
+	assert.EqualValues(t, text, string(fastCtx.Response.Body()))
+}
@@ -2,10 +2,16 @@ package gorouter
 
 import (
 	"context"
+	"errors"
+	"fmt"
 	"io"
 	"net"
 	"os"
 	"regexp"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/valyala/fasthttp"
 
@@ -16,6 +22,9 @@ import (
 
 type InitCtx func(ctx *Context) error
 
+// ErrorHandlerFunc gets the error which is returned by the handler pipeline (after the last handler).
+type ErrorHandlerFunc func(ctx *Context, err error)
+
 type Server struct {
 	shutdownLocker *int64
 
@@ -24,13 +33,46 @@ type Server struct {
 
 	srv *fasthttp.Server
 
-	tree     *Tree
 	baseAuth *BaseAuth
-	regTree  *RegTree
+
+	// routes are compiled into the table which is swapped atomically, so lookups take no locks
+	routesLock    sync.Mutex
+	routes        *RouteBuilder
+	table         atomic.Pointer[RouteTable]
+	routesChanged atomic.Bool
+	lastRoute     *Route
+
+	// strictRoutes panics on route conflicts, otherwise they are logged and collected
+	strictRoutes bool
+	routeErrors  []error
 
 	logConfig *config.Config
 	initCtx   InitCtx
 
+	notFound         *HandlerSet
+	methodNotAllowed *HandlerSet
+	errorHandler     ErrorHandlerFunc
+	last             ILastHandler
+
+	// autoMethods answers HEAD by GET handlers and OPTIONS by the list of allowed methods
+	autoMethods bool
+
+	pathPolicy      PathPolicy
+	caseInsensitive bool
+
+	// timeout is the default deadline of requests, zero means no deadline
+	timeout time.Duration
+
+	// uploads are the default limits of multipart uploads
+	uploads UploadLimits
+
+	// sseHeartbeat is the interval of heartbeats of event streams
+	sseHeartbeat time.Duration
+
+	// jsonLimit is the max size of JSON request bodies, jsonUnknownFields allows unknown fields in them
+	jsonLimit         int
+	jsonUnknownFields bool
+
 	// shutdownTimeOut is max time for shutdown server in millisecond
 	shutdownTimeOut int
 
@@ -42,13 +84,15 @@ type Server struct {
 }
 
 func New() *Server {
-	return &Server{
+	server := &Server{
 		shutdownLocker: new(int64),
-		tree:           newTree(),
-		regTree:        newRegTree(),
+		routes:         NewRouteBuilder(),
 		logConfig:      config.NewConfig(),
 		baseAuth:       NewBaseAuth(),
 	}
+	server.table.Store(server.routes.Build())
+
+	return server
 }
 
 func (server *Server) Server() *fasthttp.Server {
@@ -85,6 +129,28 @@ func (server *Server) SetServerTLS(certFile, keyFile string) *Server {
 	return server
 }
 
+func (server *Server) AutoMethods() bool {
+	return server.autoMethods
+}
+
+// SetAutoMethods turns on the automatic HEAD and OPTIONS responses.
+// HEAD falls back to the GET handler set without body and OPTIONS gets the "Allow" header with 204 status.
+// Explicitly registered HEAD and OPTIONS routes have priority over automatic ones.
+func (server *Server) SetAutoMethods(auto bool) *Server {
+	server.autoMethods = auto
+	return server
+}
+
+func (server *Server) Timeout() time.Duration {
+	return server.timeout
+}
+
+// SetTimeout sets up the default deadline of requests for routes without their own timeout.
+func (server *Server) SetTimeout(timeout time.Duration) *Server {
+	server.timeout = timeout
+	return server
+}
+
 func (server *Server) ShutdownTimeOut() int {
 	return server.shutdownTimeOut
 }
@@ -120,24 +186,222 @@ func (server *Server) Debugf(format string, data ...any) {
 	}
 }
 
+func (server *Server) Errorf(format string, data ...any) {
+	if server.logConfig.Level() >= level.ErrorLevel {
+		logger.New().
+			SetConfig(server.logConfig).
+			Errorf(format, data...)
+	}
+}
+
+func (server *Server) Warnf(format string, data ...any) {
+	if server.logConfig.Level() >= level.WarnLevel {
+		logger.New().
+			SetConfig(server.logConfig).
+			Warnf(format, data...)
+	}
+}
+
 func (server *Server) Router() *Router {
 	return newRouter(server)
 }
 
-func (server *Server) Tree() *Tree {
-	return server.tree
+// RouteTable returns the current compiled route table.
+// New routes are compiled into the new table once: by Run or on the first lookup after their registration.
+func (server *Server) RouteTable() *RouteTable {
+	if server.routesChanged.Load() {
+		server.publish()
+	}
+
+	return server.table.Load()
+}
+
+// publish compiles and publishes the table of registered routes if they are changed.
+// The flag is cleared after the new table is published, so concurrent lookups never get the old one.
+func (server *Server) publish() {
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	if server.routesChanged.Load() {
+		server.table.Store(server.routes.Build())
+		server.routesChanged.Store(false)
+	}
+}
+
+func (server *Server) addRoute(route *Route) {
+	server.addRoutes(route)
+}
+
+// addRoutes checks and adds routes, the route table is compiled later for all of them.
+func (server *Server) addRoutes(routes ...*Route) {
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	for _, route := range routes {
+		if err := checkRoute(server.routes.index, route); err != nil {
+			if server.strictRoutes || errors.Is(err, RouteSyntaxError) {
+				panic(err)
+			}
+
+			server.routeErrors = append(server.routeErrors, err)
+			server.Warnf("%s", err.Error())
+		}
+
+		// mounted routes come with their names
+		if route.Name != "" {
+			if err := server.nameConflict(route, route.Name); err != nil {
+				if server.strictRoutes {
+					panic(err)
+				}
+
+				server.routeErrors = append(server.routeErrors, err)
+				server.Warnf("%s", err.Error())
+			}
+		}
+
+		server.routes.add(route)
+		server.routesChanged.Store(true)
+		server.lastRoute = route
+	}
+}
+
+// nameRoute sets up the name of the route, the name must be unique.
+func (server *Server) nameRoute(route *Route, name string) {
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	if route == nil {
+		panic("no route for the name '" + name + "'")
+	}
+
+	if err := server.nameConflict(route, name); err != nil {
+		if server.strictRoutes {
+			panic(err)
+		}
+
+		server.routeErrors = append(server.routeErrors, err)
+		server.Warnf("%s", err.Error())
+	}
+
+	server.routes.Lock()
+	defer server.routes.Unlock()
+
+	server.routes.setName(route, name)
+}
+
+// nameConflict checks the name is not used by another route.
+func (server *Server) nameConflict(route *Route, name string) error {
+	if old := server.routes.Route(name); old != nil && old != route {
+		return fmt.Errorf("%w: name '%s' of '%s' is already used by '%s'", RouteConflictError, name, route, old)
+	}
+
+	return nil
+}
+
+// Name sets up the name of the last route which is added to the server. The name is used to build URLs.
+func (server *Server) Name(name string) *Server {
+	server.nameRoute(server.lastRoute, name)
+	return server
+}
+
+// RouteBuilder returns the copy of the builder with all registered routes. It may be changed and used by Reload.
+func (server *Server) RouteBuilder() *RouteBuilder {
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	return server.routes.Clone()
+}
+
+// Reload replaces all routes of the server by the routes from the builder.
+// The new route table is swapped atomically, requests in progress keep the old one.
+// Routes with syntax errors (and with conflicts in strict mode) are not loaded.
+func (server *Server) Reload(builder *RouteBuilder) error {
+	if err := builder.Validate(); err != nil {
+		if server.strictRoutes || errors.Is(err, RouteSyntaxError) {
+			return err
+		}
+
+		server.Warnf("%s", err.Error())
+	}
+
+	routes := builder.Clone()
+	table := routes.Build()
+
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	server.routes = routes
+	server.table.Store(table)
+	server.routesChanged.Store(false)
+
+	return nil
+}
+
+func (server *Server) StrictRoutes() bool {
+	return server.strictRoutes
+}
+
+// SetStrictRoutes turns on the panic on route conflicts: ambiguous param names, duplicated routes
+// and shadowed regexp routes. It's useful for CI, by default conflicts are logged only.
+func (server *Server) SetStrictRoutes(strict bool) *Server {
+	server.strictRoutes = strict
+	return server
+}
+
+// RouteErrors returns all route conflicts which were found while routes were registered.
+func (server *Server) RouteErrors() []error {
+	server.routesLock.Lock()
+	defer server.routesLock.Unlock()
+
+	return append([]error{}, server.routeErrors...)
 }
 
 func (server *Server) Find(method Method, path string, args *fasthttp.Args) TreeResult {
-	if res := server.tree.Find(method, path, args); res.Find {
-		return res
+	return server.RouteTable().Find(method, path, args)
+}
+
+// FindHost looks for the route in tables of all matched host patterns, then in the fallback routes (without host).
+// Captured host params are added to args.
+func (server *Server) FindHost(host string, method Method, path string, args *fasthttp.Args) TreeResult {
+	return server.RouteTable().FindHost(host, method, path, args)
+}
+
+// Host returns the router for the host pattern: "api.example.com", "*.example.com" or ":tenant.example.com".
+func (server *Server) Host(host string) *Router {
+	return server.Router().Host(host)
+}
+
+// Allowed returns the sorted list of methods registered for the path in both trees.
+// An empty list means that the path is unknown.
+func (server *Server) Allowed(path string) []Method {
+	return server.AllowedHost("", path)
+}
+
+// AllowedHost returns the sorted list of methods registered for the path of the host, see Allowed.
+func (server *Server) AllowedHost(host, path string) []Method {
+	found := map[Method]bool{}
+	for _, m := range server.RouteTable().MethodsHost(host, path) {
+		found[m] = true
 	}
 
-	if res := server.regTree.Find(method, path, args); res.Find {
-		return res
+	if server.autoMethods && len(found) > 0 {
+		if found[MethodGet] {
+			found[MethodHead] = true
+		}
+		found[MethodOptions] = true
 	}
 
-	return TreeResult{}
+	return sortMethods(found)
+}
+
+// allowHeader joins methods for the "Allow" response header.
+func allowHeader(methods []Method) string {
+	out := make([]string, len(methods))
+	for i := range methods {
+		out[i] = string(methods[i])
+	}
+
+	return strings.Join(out, ", ")
 }
 
 func resolveAddress(addr []string) string {
@@ -155,23 +419,45 @@ func resolveAddress(addr []string) string {
 }
 
 func (server *Server) Add(method Method, route string, handler IRunHandler, set *HandlerSet) *Server {
-	server.tree.Add(method, route, set.Clone().Use(handler))
+	server.addRoute(&Route{Method: method, Path: route, HandlerSet: set.Clone().Use(handler)})
 
 	return server
 }
 
 func (server *Server) AddReg(method Method, route *regexp.Regexp, handler IRunHandler, set *HandlerSet) *Server {
-	server.regTree.Add(method, route, set.Clone().Use(handler))
+	server.addRoute(&Route{Method: method, Reg: route, HandlerSet: set.Clone().Use(handler)})
+
+	return server
+}
+
+// AddRegPriority adds the regexp route which is checked before all routes with the lower priority.
+func (server *Server) AddRegPriority(method Method, route *regexp.Regexp, priority int, handler IRunHandler, set *HandlerSet) *Server {
+	server.addRoute(&Route{Method: method, Reg: route, Priority: priority, HandlerSet: set.Clone().Use(handler)})
 
 	return server
 }
 
+// nonEmptySet returns the passed set or the empty one.
 func nonEmptySet(set ...*HandlerSet) *HandlerSet {
-	if len(set) > 0 {
+	if len(set) > 0 && set[0] != nil {
 		return set[0]
 	}
 
-	return nil
+	return Set("")
+}
+
+// Match adds the route for all methods of the list, standard and extension ones: "PROPFIND", "PURGE".
+func (server *Server) Match(methods []Method, route string, handler IRunHandler, set ...*HandlerSet) *Server {
+	for _, method := range methods {
+		server.Add(method, route, handler, nonEmptySet(set...))
+	}
+
+	return server
+}
+
+// Any adds the route for all standard methods, see StandardMethods.
+func (server *Server) Any(route string, handler IRunHandler, set ...*HandlerSet) *Server {
+	return server.Match(StandardMethods, route, handler, set...)
 }
 
 func (server *Server) GetPost(route string, handler IRunHandler, set ...*HandlerSet) *Server {
@@ -238,3 +524,38 @@ func (server *Server) SesInit(initCtx InitCtx) *Server {
 	server.initCtx = initCtx
 	return server
 }
+
+// NotFound sets up the handler for unknown paths. The response status is 404 before the handler is called.
+func (server *Server) NotFound(handler IRunHandler, set ...*HandlerSet) *Server {
+	server.notFound = systemSet("not found", handler, set...)
+	return server
+}
+
+// MethodNotAllowed sets up the handler for known paths with the unknown method.
+// The response status is 405 and the "Allow" header is filled before the handler is called.
+func (server *Server) MethodNotAllowed(handler IRunHandler, set ...*HandlerSet) *Server {
+	server.methodNotAllowed = systemSet("method not allowed", handler, set...)
+	return server
+}
+
+// ErrorHandler sets up the single handler for all errors of requests: errors which are returned from
+// the last handler or from the aborted pipeline, errors of SesInit, recovered panics and built-in 404/405 responses.
+func (server *Server) ErrorHandler(handler ErrorHandlerFunc) *Server {
+	server.errorHandler = handler
+	return server
+}
+
+// Last sets up the default last handler for routes which have no own last handler, e.g. NewProblemHandler().
+func (server *Server) Last(handler ILastHandler) *Server {
+	server.last = handler
+	return server
+}
+
+// systemSet returns the set of the system handler, the set gets the id if it's not passed.
+func systemSet(id string, handler IRunHandler, set ...*HandlerSet) *HandlerSet {
+	if len(set) > 0 && set[0] != nil {
+		return set[0].Clone().Use(handler)
+	}
+
+	return Set(id).Use(handler)
+}
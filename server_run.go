@@ -7,6 +7,7 @@ import (
 	"net"
 	"os"
 	"os/signal"
+	"runtime/debug"
 	"sync/atomic"
 	"syscall"
 	"time"
@@ -29,6 +30,9 @@ func (server *Server) Run(ctx context.Context, add string, addr ...string) error
 		server.listener = ln
 	}
 
+	// routes are compiled before the first request
+	server.publish()
+
 	errGroup, errCtx := errgroup.WithContext(ctx)
 	server.ctx = errCtx
 
@@ -116,52 +120,196 @@ func (server *Server) ServeHTTP(fastCtx *fasthttp.RequestCtx) {
 		}
 	}
 
-	_, err := server.runHTTP(fastCtx)
-	printError(fastCtx, err)
+	// recovered panics are logged with the stack where they are recovered
+	if _, err := server.runHTTP(fastCtx); asPanic(err) == nil {
+		printError(fastCtx, err)
+	}
 }
 
-func (server *Server) runHTTP(fastCtx *fasthttp.RequestCtx) (*Context, error) {
+func (server *Server) runHTTP(fastCtx *fasthttp.RequestCtx) (ctx *Context, err error) {
 	urlIDsArgs := fasthttp.AcquireArgs()
 	defer fasthttp.ReleaseArgs(urlIDsArgs)
 
+	// the last chance for panics outside of handler pipelines
+	defer func() {
+		if r := recover(); r != nil {
+			pe := &PanicError{Value: r, Stack: debug.Stack()}
+			fastCtx.Error(fasthttp.StatusMessage(fasthttp.StatusInternalServerError), fasthttp.StatusInternalServerError)
+			server.Errorf("recovered panic in '%s': %v\n%s", string(fastCtx.Path()), r, string(pe.Stack))
+			ctx, err = server.handleError(fastCtx, nil, urlIDsArgs, pe)
+		}
+	}()
+
 	path := string(fastCtx.Path())
-	set := server.Find(Method(fastCtx.Method()), path, urlIDsArgs)
-	if !set.Find {
-		// TODO not found handler
+	host := string(fastCtx.Host())
+	method := Method(fastCtx.Method())
+	set := server.FindHost(host, method, path, urlIDsArgs)
+	if set.Find {
+		return server.runFound(fastCtx, path, set, urlIDsArgs)
+	}
+
+	if server.caseInsensitive {
+		if res, registered := server.RouteTable().FindHostFold(host, method, path, urlIDsArgs); res.Find {
+			redirectPath(fastCtx, registered)
+			return nil, nil
+		}
+	}
+
+	if server.autoMethods {
+		switch method {
+		case MethodHead:
+			if set = server.FindHost(host, MethodGet, path, urlIDsArgs); set.Find {
+				fastCtx.Response.SkipBody = true
+				return server.runFound(fastCtx, path, set, urlIDsArgs)
+			}
+		case MethodOptions:
+			if allowed := server.AllowedHost(host, path); len(allowed) > 0 {
+				fastCtx.SetStatusCode(fasthttp.StatusNoContent)
+				fastCtx.Response.Header.Set(fasthttp.HeaderAllow, allowHeader(allowed))
+				return nil, nil
+			}
+		}
+	}
+
+	// the path is known, but the method is not registered for it
+	if allowed := server.AllowedHost(host, path); len(allowed) > 0 {
+		if server.methodNotAllowed == nil {
+			fastCtx.Error("method not allowed", fasthttp.StatusMethodNotAllowed)
+			fastCtx.Response.Header.Set(fasthttp.HeaderAllow, allowHeader(allowed))
+			return server.handleError(fastCtx, nil, urlIDsArgs,
+				errors.New("method '"+string(fastCtx.Method())+"' not allowed for path '"+path+"'"))
+		}
+
+		fastCtx.SetStatusCode(fasthttp.StatusMethodNotAllowed)
+		fastCtx.Response.Header.Set(fasthttp.HeaderAllow, allowHeader(allowed))
+		return server.runSet(fastCtx, path, server.methodNotAllowed, urlIDsArgs)
+	}
+
+	return server.runNotFound(fastCtx, path, urlIDsArgs)
+}
+
+// handleError is the single place where errors of requests go: the error handler of the server gets them,
+// otherwise they are returned to be printed. The built-in 404 and 405 responses have no context,
+// so the new one is created for the error handler.
+func (server *Server) handleError(fastCtx *fasthttp.RequestCtx, ctx *Context, urlIDsArgs *fasthttp.Args, err error) (*Context, error) {
+	if err == nil || server.errorHandler == nil {
+		return ctx, err
+	}
+
+	if ctx == nil {
+		ctx, _ = newContext(server.ctx, fastCtx, urlIDsArgs, 0)
+		defer ctx.cancel()
+
+		ctx.server = server
+		ctx.SetLogger(logger.New().SetConfig(server.logConfig)).SetLoggerLevel(server.logConfig.Level())
+	}
+
+	server.errorHandler(ctx, err)
+	return ctx, nil
+}
+
+// runFound runs the found route if its path is allowed by the path policy.
+func (server *Server) runFound(fastCtx *fasthttp.RequestCtx, path string, res TreeResult, urlIDsArgs *fasthttp.Args) (*Context, error) {
+	if server.checkPath(fastCtx, path, res) {
+		return server.runSet(fastCtx, path, res.HandlerSet, urlIDsArgs)
+	}
+
+	if server.pathPolicy == PathRedirect {
+		return nil, nil
+	}
+
+	return server.runNotFound(fastCtx, path, urlIDsArgs)
+}
+
+func (server *Server) runNotFound(fastCtx *fasthttp.RequestCtx, path string, urlIDsArgs *fasthttp.Args) (*Context, error) {
+	if server.notFound == nil {
 		fastCtx.Error("not found", fasthttp.StatusNotFound)
-		return nil, errors.New("path '" + path + "' not found")
+		return server.handleError(fastCtx, nil, urlIDsArgs, errors.New("path '"+path+"' not found"))
 	}
 
-	ctx, err := NewContext(server.ctx, fastCtx, urlIDsArgs)
-	defer ctx.cancel()
+	fastCtx.SetStatusCode(fasthttp.StatusNotFound)
+	return server.runSet(fastCtx, path, server.notFound, urlIDsArgs)
+}
+
+// runSet runs the full pipeline of the handler set: before, main, after and last handlers.
+func (server *Server) runSet(fastCtx *fasthttp.RequestCtx, path string, set *HandlerSet, urlIDsArgs *fasthttp.Args) (*Context, error) {
+	timeout := set.GetTimeout()
+	if timeout <= 0 {
+		timeout = server.timeout
+	}
+
+	ctx, err := newContext(server.ctx, fastCtx, urlIDsArgs, timeout)
+	defer ctx.finish()
 	if err != nil {
 		fastCtx.Error("not found", fasthttp.StatusBadRequest)
-		return nil, errors.New("path '" + path + "': " + err.Error())
+		return server.handleError(fastCtx, nil, urlIDsArgs, errors.New("path '"+path+"': "+err.Error()))
+	}
+
+	ctx.server = server
+	lg := logger.New().SetConfig(server.logConfig)
+	ctx.SetLogger(lg).SetLoggerLevel(server.logConfig.Level())
+	if timeout > 0 {
+		lg.Add("timeout", timeout.String())
+	}
+
+	ctx.uploads = server.uploads
+	if limits := set.GetUploadLimits(); limits != nil {
+		ctx.uploads = *limits
 	}
+	defer ctx.removeTempFiles()
 
 	// add to context additional data
 	if server.initCtx != nil {
 		if err := server.initCtx(ctx); err != nil {
-
-			return nil, errors.New("path '" + path + "': " + err.Error())
+			return server.handleError(fastCtx, ctx, urlIDsArgs, errors.New("path '"+path+"': "+err.Error()))
 		}
 	}
 
-	lg := logger.New().SetConfig(server.logConfig)
-	ctx.SetLogger(lg).SetLoggerLevel(server.logConfig.Level())
+	// panics of handlers are recovered, they go to the last handler as PanicError
+	err = safeRun(func() error { return set.Run(ctx) })
+	if pe := asPanic(err); pe != nil {
+		ctx.onPanic(pe)
+	}
 
-	err = set.HandlerSet.Run(ctx)
 	if ctx.isAborted {
-		return ctx, err
+		return server.handleError(fastCtx, ctx, urlIDsArgs, err)
 	}
 
-	if egErr := ctx.EGWait(); err == nil {
+	egErr := ctx.EGWait()
+	if pe := asPanic(egErr); pe != nil && asPanic(err) == nil {
+		ctx.onPanic(pe)
+		err = pe
+	} else if err == nil {
 		err = egErr
-	} else if err != nil && egErr != nil {
+	} else if egErr != nil {
 		err = errors.Wrap(err, egErr.Error())
 	}
 
-	err = set.HandlerSet.RunLast(ctx, err)
+	if ctx.TimedOut() {
+		err = ctx.timeoutError(err)
+	}
+
+	recovered := asPanic(err)
+	err = safeRun(func() error { return set.runLast(ctx, err, server.last) })
+	if pe := asPanic(err); pe != nil && pe != recovered {
+		ctx.onPanic(pe)
+	}
+
+	// HTTPError sets the status and the body, the error handler may change them later
+	he := asHTTPError(err)
+	if he != nil {
+		he.Render(ctx)
+	}
+
+	// the error handler takes care of the error, so there is nothing to print
+	if err != nil && server.errorHandler != nil {
+		return server.handleError(fastCtx, ctx, urlIDsArgs, err)
+	}
+
+	// client errors are the usual answers, they aren't printed
+	if he != nil && he.Code < fasthttp.StatusInternalServerError {
+		return ctx, nil
+	}
 
 	return ctx, err
 }
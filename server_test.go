@@ -0,0 +1,293 @@
+package gorouter
+
+import (
+	"errors"
+	"regexp"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type WriteHandler struct {
+	RunHandler
+
+	Body string
+}
+
+func (h *WriteHandler) Run(ctx *Context) error {
+	_, err := ctx.WriteString(h.Body)
+	return err
+}
+
+func newWH(body string) *WriteHandler {
+	return &WriteHandler{Body: body}
+}
+
+type FailHandler struct {
+	RunHandler
+
+	Err error
+}
+
+func (h *FailHandler) Run(_ *Context) error {
+	return h.Err
+}
+
+type HeaderHandler struct {
+	Handler
+
+	Key, Value string
+}
+
+func (h *HeaderHandler) Run(ctx *Context) error {
+	ctx.FastCtx().Response.Header.Set(h.Key, h.Value)
+	return nil
+}
+
+type ParamsHandler struct {
+	RunHandler
+
+	Keys []string
+}
+
+func (h *ParamsHandler) Run(ctx *Context) error {
+	for i, key := range h.Keys {
+		if i > 0 {
+			_, _ = ctx.WriteString(",")
+		}
+		_, _ = ctx.WriteString(key + "=" + ctx.PeekStringParam(key))
+	}
+
+	return nil
+}
+
+// serveTest runs the request through the server without network, the prepare functions set up the request.
+func serveTest(server *Server, method, uri string, prepare ...func(req *fasthttp.Request)) *fasthttp.RequestCtx {
+	req := &fasthttp.Request{}
+	req.Header.SetMethod(method)
+	req.SetRequestURI(uri)
+
+	fastCtx := &fasthttp.RequestCtx{}
+	fastCtx.Init(req, nil, nil)
+	for _, f := range prepare {
+		f(&fastCtx.Request)
+	}
+	server.ServeHTTP(fastCtx)
+
+	return fastCtx
+}
+
+func withHeader(key, value string) func(req *fasthttp.Request) {
+	return func(req *fasthttp.Request) {
+		req.Header.Set(key, value)
+	}
+}
+
+func withBody(contentType, body string) func(req *fasthttp.Request) {
+	return func(req *fasthttp.Request) {
+		req.Header.SetContentType(contentType)
+		req.SetBodyString(body)
+	}
+}
+
+func withForm(form string) func(req *fasthttp.Request) {
+	return withBody("application/x-www-form-urlencoded", form)
+}
+
+func TestServerMethodNotAllowed(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", newWH("get user")).
+		Delete("/user/:id", newWH("delete user")).
+		UseReg(MethodPut, regexp.MustCompile(`^/user/\d+$`), newWH("put user"))
+
+	fastCtx := serveTest(g, MethodGet, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "get user", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodPost, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "DELETE, GET, PUT", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	fastCtx = serveTest(g, MethodPost, "/user/abc")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "DELETE, GET", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	fastCtx = serveTest(g, MethodGet, "/unknown")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+}
+
+func TestServerNotFoundHandlers(t *testing.T) {
+	g := New()
+	g.Router().Get("/user/:id", newWH("get user"))
+
+	g.NotFound(newWH("custom not found"), Set("").Before(&HeaderHandler{Key: "X-Before", Value: "1"}))
+	g.MethodNotAllowed(newWH("custom method not allowed"))
+
+	fastCtx := serveTest(g, MethodGet, "/unknown")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "custom not found", string(fastCtx.Response.Body()))
+	assert.EqualValues(t, "1", string(fastCtx.Response.Header.Peek("X-Before")))
+
+	fastCtx = serveTest(g, MethodPost, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "custom method not allowed", string(fastCtx.Response.Body()))
+	assert.EqualValues(t, "GET", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	// the handler without the set gets the id of the system handler
+	assert.EqualValues(t, "", g.notFound.ID)
+	assert.EqualValues(t, "method not allowed", g.methodNotAllowed.ID)
+
+	g.NotFound(newWH("custom not found"))
+	assert.EqualValues(t, "not found", g.notFound.ID)
+}
+
+func TestServerErrorHandler(t *testing.T) {
+	g := New()
+	g.Router().Get("/fail", &FailHandler{Err: errors.New("fail")})
+
+	var handled error
+	g.ErrorHandler(func(ctx *Context, err error) {
+		handled = err
+		ctx.FastCtx().SetStatusCode(fasthttp.StatusInternalServerError)
+		_, _ = ctx.WriteString(`{"error":"` + err.Error() + `"}`)
+	})
+
+	fastCtx := serveTest(g, MethodGet, "/fail")
+	assert.EqualValues(t, fasthttp.StatusInternalServerError, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, `{"error":"fail"}`, string(fastCtx.Response.Body()))
+	assert.EqualError(t, handled, "fail")
+}
+
+type AbortHandler struct {
+	RunHandler
+}
+
+func (h *AbortHandler) Run(ctx *Context) error {
+	ctx.Abort()
+	return Forbidden("")
+}
+
+func TestServerErrorHandlerBuiltIn(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", newWH("get user")).
+		Get("/abort", &AbortHandler{})
+
+	var handled []string
+	g.ErrorHandler(func(ctx *Context, err error) {
+		handled = append(handled, err.Error())
+		_, _ = ctx.WriteString(" handled")
+	})
+
+	fastCtx := serveTest(g, MethodGet, "/unknown")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "not found handled", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodPost, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "GET", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+	assert.EqualValues(t, "method not allowed handled", string(fastCtx.Response.Body()))
+
+	serveTest(g, MethodGet, "/abort")
+
+	g.SesInit(func(ctx *Context) error { return errors.New("no session") })
+	serveTest(g, MethodGet, "/user/15")
+
+	assert.EqualValues(t, []string{
+		"path '/unknown' not found",
+		"method 'POST' not allowed for path '/user/15'",
+		"403 Forbidden",
+		"path '/user/15': no session",
+	}, handled)
+}
+
+func TestServerAutoMethods(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", newWH("get user")).
+		Post("/user/:id", newWH("post user")).
+		Get("/item/:id", newWH("get item")).
+		Options("/item/:id", newWH("options item"))
+
+	fastCtx := serveTest(g, MethodHead, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode(), "auto mode is off")
+
+	g.SetAutoMethods(true)
+
+	fastCtx = serveTest(g, MethodHead, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, true, fastCtx.Response.SkipBody)
+
+	fastCtx = serveTest(g, MethodOptions, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusNoContent, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "GET, HEAD, OPTIONS, POST", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	fastCtx = serveTest(g, MethodOptions, "/item/15")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "options item", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodDelete, "/user/15")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "GET, HEAD, OPTIONS, POST", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	fastCtx = serveTest(g, MethodOptions, "/unknown")
+	assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode())
+}
+
+func TestServerRegNamedGroups(t *testing.T) {
+	g := New()
+	g.Router().
+		Get("/user/:id", &ParamsHandler{Keys: []string{"id"}}).
+		UseReg(MethodGet, regexp.MustCompile(`^/item/(?P<id>\d+)(/(?P<page>\d+))?$`), &ParamsHandler{Keys: []string{"id", "page"}})
+
+	fastCtx := serveTest(g, MethodGet, "/user/15")
+	assert.EqualValues(t, "id=15", string(fastCtx.Response.Body()))
+
+	fastCtx = serveTest(g, MethodGet, "/item/16/2")
+	assert.EqualValues(t, "id=16,page=2", string(fastCtx.Response.Body()))
+
+	// "page" is taken from the query when the optional group doesn't match
+	fastCtx = serveTest(g, MethodGet, "/item/17?page=4")
+	assert.EqualValues(t, "id=17,page=4", string(fastCtx.Response.Body()))
+}
+
+func TestServerExtensionMethods(t *testing.T) {
+	g := New()
+	g.Router().
+		Use("PROPFIND", "/dav/*path", newWH("propfind")).
+		UseReg("PURGE", regexp.MustCompile(`^/cache/.+$`), newWH("purge")).
+		Match([]Method{MethodPut, "MKCOL"}, "/dav/:dir/new", newWH("mkcol")).
+		Any("/any", newWH("any"))
+
+	g.Any("/server/any", newWH("server any"))
+
+	data := map[string][3]string{
+		"propfind":   {"PROPFIND", "/dav/a/b", "propfind"},
+		"purge":      {"PURGE", "/cache/img.png", "purge"},
+		"mkcol":      {"MKCOL", "/dav/a/new", "mkcol"},
+		"put":        {MethodPut, "/dav/a/new", "mkcol"},
+		"any":        {MethodDelete, "/any", "any"},
+		"server any": {MethodTrace, "/server/any", "server any"},
+	}
+
+	for name, req := range data {
+		fastCtx := serveTest(g, req[0], req[1])
+		assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode(), name)
+		assert.EqualValues(t, req[2], string(fastCtx.Response.Body()), name)
+	}
+
+	fastCtx := serveTest(g, MethodGet, "/dav/a/new")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "MKCOL, PROPFIND, PUT", string(fastCtx.Response.Header.Peek(fasthttp.HeaderAllow)))
+
+	fastCtx = serveTest(g, "PROPFIND", "/any")
+	assert.EqualValues(t, fasthttp.StatusMethodNotAllowed, fastCtx.Response.StatusCode())
+
+	assert.True(t, ValidMethod("VERSION-CONTROL"))
+	assert.False(t, ValidMethod("BAD METHOD"))
+	assert.False(t, ValidMethod(""))
+	assert.Panics(t, func() { g.Router().Use("BAD METHOD", "/bad", newWH("bad")) })
+}
@@ -0,0 +1,398 @@
+package gorouter
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/valyala/fasthttp"
+)
+
+// DefaultSSEHeartbeat is the interval of heartbeat comments of event streams if the server has no own one.
+const DefaultSSEHeartbeat = 15 * time.Second
+
+// sseBuffer is the number of events which are waiting to be sent to the client.
+const sseBuffer = 64
+
+var SSEClosedError = errors.New("event stream is closed")
+
+var sseHeartbeatFrame = []byte(": ping\n\n")
+
+// SSEEvent is the single server-sent event.
+type SSEEvent struct {
+	ID    string        // sets up Last-Event-ID of the client
+	Event string        // event type, "message" is used by clients if it's empty
+	Data  any           // string and []byte are sent as is, other values are sent as JSON
+	Retry time.Duration // reconnection time of the client, zero is not sent
+}
+
+// frame encodes the event in the text/event-stream format.
+func (ev *SSEEvent) frame() ([]byte, error) {
+	buf := &bytes.Buffer{}
+
+	if ev.ID != "" {
+		buf.WriteString("id: " + sseField(ev.ID) + "\n")
+	}
+	if ev.Event != "" {
+		buf.WriteString("event: " + sseField(ev.Event) + "\n")
+	}
+	if ev.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(ev.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	var data string
+	switch v := ev.Data.(type) {
+	case nil:
+	case string:
+		data = v
+	case []byte:
+		data = string(v)
+	default:
+		body, err := jsonAPI.Marshal(v)
+		if err != nil {
+			return nil, err
+		}
+		data = string(body)
+	}
+
+	data = strings.ReplaceAll(strings.ReplaceAll(data, "\r\n", "\n"), "\r", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	return buf.Bytes(), nil
+}
+
+// sseField removes line breaks which would break the framing.
+func sseField(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
+
+func (server *Server) SSEHeartbeat() time.Duration {
+	return server.sseHeartbeat
+}
+
+// SetSSEHeartbeat sets up the interval of heartbeat comments of event streams,
+// DefaultSSEHeartbeat is used if it's zero, negative value turns heartbeats off.
+func (server *Server) SetSSEHeartbeat(heartbeat time.Duration) *Server {
+	server.sseHeartbeat = heartbeat
+	return server
+}
+
+// SSEWriter sends server-sent events to the client. Events are queued and written by the response stream,
+// so Send doesn't wait for the network. The stream ends on Close, on the client disconnect, on ctx.Abort()
+// or on the timeout of the route.
+type SSEWriter struct {
+	ctx       *Context
+	streamCtx context.Context
+	cancel    context.CancelFunc
+	events    chan []byte
+	closed    chan struct{}
+	closeOnce sync.Once
+	heartbeat time.Duration
+}
+
+// SSE starts the event stream of the response. Events are usually sent by the goroutine which is started
+// by the handler, the handler returns right away. The route should not have the timeout.
+func (ctx *Context) SSE() *SSEWriter {
+	if ctx.sse != nil {
+		return ctx.sse
+	}
+
+	heartbeat := DefaultSSEHeartbeat
+	if ctx.server != nil && ctx.server.sseHeartbeat != 0 {
+		heartbeat = ctx.server.sseHeartbeat
+	}
+
+	// ctx.Ctx() is canceled when the handlers finish, the stream lives longer
+	streamCtx, cancel := context.WithCancel(ctx.cancelCtx)
+
+	w := &SSEWriter{
+		ctx:       ctx,
+		streamCtx: streamCtx,
+		cancel:    cancel,
+		events:    make(chan []byte, sseBuffer),
+		closed:    make(chan struct{}),
+		heartbeat: heartbeat,
+	}
+	ctx.sse = w
+
+	header := &ctx.fastCtx.Response.Header
+	header.SetContentType("text/event-stream")
+	header.Set(fasthttp.HeaderCacheControl, "no-cache")
+	header.Set("X-Accel-Buffering", "no")
+	ctx.fastCtx.SetStatusCode(fasthttp.StatusOK)
+	ctx.fastCtx.SetBodyStreamWriter(w.stream)
+
+	return w
+}
+
+// finish cancels the request context when the request handlers finish.
+// The context of the running event stream is canceled when the stream ends.
+func (ctx *Context) finish() {
+	if ctx.sse == nil {
+		ctx.cancel()
+		return
+	}
+
+	// the error response replaces the stream
+	if !ctx.fastCtx.Response.IsBodyStream() {
+		ctx.sse.cancel()
+		ctx.cancel()
+	}
+}
+
+// LastEventID returns the id of the last event which the reconnected client has received.
+func (w *SSEWriter) LastEventID() string {
+	return string(w.ctx.fastCtx.Request.Header.Peek("Last-Event-ID"))
+}
+
+// Context returns the context of the stream, use it instead of ctx.Ctx() in goroutines which send events.
+func (w *SSEWriter) Context() context.Context {
+	return w.streamCtx
+}
+
+// Done is closed when the stream ends.
+func (w *SSEWriter) Done() <-chan struct{} {
+	return w.streamCtx.Done()
+}
+
+// Send queues the event, it waits if the queue is full. SSEClosedError is returned if the stream is ended.
+func (w *SSEWriter) Send(ev SSEEvent) error {
+	frame, err := ev.frame()
+	if err != nil {
+		return err
+	}
+
+	return w.send(frame)
+}
+
+// Comment sends the comment line, clients ignore it.
+func (w *SSEWriter) Comment(text string) error {
+	return w.send([]byte(": " + sseField(text) + "\n\n"))
+}
+
+func (w *SSEWriter) send(frame []byte) error {
+	select {
+	case <-w.closed:
+		return SSEClosedError
+	case <-w.streamCtx.Done():
+		return SSEClosedError
+	default:
+	}
+
+	select {
+	case w.events <- frame:
+		return nil
+	case <-w.closed:
+		return SSEClosedError
+	case <-w.streamCtx.Done():
+		return SSEClosedError
+	}
+}
+
+// trySend queues the frame if there is the room for it.
+func (w *SSEWriter) trySend(frame []byte) bool {
+	select {
+	case <-w.closed:
+		return false
+	case <-w.streamCtx.Done():
+		return false
+	default:
+	}
+
+	select {
+	case w.events <- frame:
+		return true
+	default:
+		return false
+	}
+}
+
+// Close ends the stream after the queued events are sent.
+func (w *SSEWriter) Close() {
+	w.closeOnce.Do(func() { close(w.closed) })
+}
+
+// stream writes queued events and heartbeats to the response until the stream ends.
+func (w *SSEWriter) stream(bw *bufio.Writer) {
+	defer w.cancel()
+	defer w.ctx.cancel()
+
+	write := func(frame []byte) bool {
+		if _, err := bw.Write(frame); err != nil {
+			return false
+		}
+		return bw.Flush() == nil
+	}
+
+	var heartbeat <-chan time.Time
+	if w.heartbeat > 0 {
+		ticker := time.NewTicker(w.heartbeat)
+		defer ticker.Stop()
+		heartbeat = ticker.C
+	}
+
+	for {
+		select {
+		case frame := <-w.events:
+			if !write(frame) {
+				return
+			}
+		case <-heartbeat:
+			if !write(sseHeartbeatFrame) {
+				return
+			}
+		case <-w.closed:
+			for {
+				select {
+				case frame := <-w.events:
+					if !write(frame) {
+						return
+					}
+				default:
+					return
+				}
+			}
+		case <-w.streamCtx.Done():
+			return
+		}
+	}
+}
+
+// SSEHub fans events out to many event streams. It keeps the history of the last events
+// to resume streams of reconnected clients by Last-Event-ID.
+// Slow subscribers which don't keep up with events are closed, their clients reconnect and resume.
+type SSEHub struct {
+	sync.RWMutex
+
+	subscribers map[*SSEWriter]struct{}
+	history     []sseHubEvent
+	historySize int
+	lastID      uint64
+}
+
+type sseHubEvent struct {
+	id    string
+	frame []byte
+}
+
+// NewSSEHub creates the hub which keeps historySize last events for resuming.
+func NewSSEHub(historySize int) *SSEHub {
+	return &SSEHub{
+		subscribers: map[*SSEWriter]struct{}{},
+		historySize: historySize,
+	}
+}
+
+// Subscribe adds the stream to the hub. Events after Last-Event-ID of the request are sent first
+// if they are in the history. The stream is removed from the hub when it ends.
+func (hub *SSEHub) Subscribe(w *SSEWriter) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	if lastID := w.LastEventID(); lastID != "" {
+		var replay []byte
+		for i := range hub.history {
+			if hub.history[i].id == lastID {
+				for _, ev := range hub.history[i+1:] {
+					replay = append(replay, ev.frame...)
+				}
+				break
+			}
+		}
+
+		if len(replay) > 0 && !w.trySend(replay) {
+			w.Close()
+			return
+		}
+	}
+
+	hub.subscribers[w] = struct{}{}
+
+	go func() {
+		<-w.Done()
+		hub.Unsubscribe(w)
+	}()
+}
+
+func (hub *SSEHub) Unsubscribe(w *SSEWriter) {
+	hub.Lock()
+	defer hub.Unlock()
+
+	delete(hub.subscribers, w)
+}
+
+// Len returns the number of subscribers.
+func (hub *SSEHub) Len() int {
+	hub.RLock()
+	defer hub.RUnlock()
+
+	return len(hub.subscribers)
+}
+
+// Publish sends the event to all subscribers, the event gets the sequential id if it has no own one.
+func (hub *SSEHub) Publish(ev SSEEvent) error {
+	hub.Lock()
+	defer hub.Unlock()
+
+	hub.lastID++
+	if ev.ID == "" {
+		ev.ID = strconv.FormatUint(hub.lastID, 10)
+	}
+
+	frame, err := ev.frame()
+	if err != nil {
+		return err
+	}
+
+	if hub.historySize > 0 {
+		hub.history = append(hub.history, sseHubEvent{id: sseField(ev.ID), frame: frame})
+		if len(hub.history) > hub.historySize {
+			hub.history = hub.history[len(hub.history)-hub.historySize:]
+		}
+	}
+
+	for w := range hub.subscribers {
+		if !w.trySend(frame) {
+			w.Close()
+			delete(hub.subscribers, w)
+		}
+	}
+
+	return nil
+}
+
+// Run publishes events from the source until it's closed or the context is done.
+func (hub *SSEHub) Run(ctx context.Context, source <-chan SSEEvent) error {
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case ev, ok := <-source:
+			if !ok {
+				return nil
+			}
+			if err := hub.Publish(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// Close ends all streams of the hub.
+func (hub *SSEHub) Close() {
+	hub.Lock()
+	defer hub.Unlock()
+
+	for w := range hub.subscribers {
+		w.Close()
+		delete(hub.subscribers, w)
+	}
+}
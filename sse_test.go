@@ -0,0 +1,171 @@
+package gorouter
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type SSEHandler struct {
+	RunHandler
+
+	Stream func(w *SSEWriter) // runs in its own goroutine
+	Hub    *SSEHub
+	Err    error
+
+	writer chan *SSEWriter
+}
+
+func (h *SSEHandler) Run(ctx *Context) error {
+	w := ctx.SSE()
+	if h.writer != nil {
+		h.writer <- w
+	}
+
+	if h.Hub != nil {
+		h.Hub.Subscribe(w)
+	}
+
+	if h.Stream != nil {
+		go h.Stream(w)
+	}
+
+	return h.Err
+}
+
+func waitDone(t *testing.T, done <-chan struct{}) {
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		assert.Fail(t, "the stream is not finished")
+	}
+}
+
+func TestSSEEventFrame(t *testing.T) {
+	data := []struct {
+		event    SSEEvent
+		expected string
+	}{
+		{SSEEvent{Data: "hello"}, "data: hello\n\n"},
+		{SSEEvent{ID: "7", Event: "update", Data: "a\nb\r\nc", Retry: 3 * time.Second}, "id: 7\nevent: update\nretry: 3000\ndata: a\ndata: b\ndata: c\n\n"},
+		{SSEEvent{ID: "1\n2", Data: map[string]int{"n": 1}}, "id: 12\ndata: {\"n\":1}\n\n"},
+		{SSEEvent{Event: "ping"}, "event: ping\ndata: \n\n"},
+	}
+
+	for _, d := range data {
+		frame, err := d.event.frame()
+		assert.Nil(t, err)
+		assert.EqualValues(t, d.expected, string(frame))
+	}
+}
+
+func TestSSE(t *testing.T) {
+	g := New().SetSSEHeartbeat(-1)
+	g.Router().Get("/events", &SSEHandler{Stream: func(w *SSEWriter) {
+		defer w.Close()
+
+		_ = w.Send(SSEEvent{ID: "1", Event: "count", Data: 1})
+		_ = w.Comment("two is next")
+		_ = w.Send(SSEEvent{ID: "2", Event: "count", Data: 2})
+	}})
+
+	fastCtx := serveTest(g, MethodGet, "/events")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "text/event-stream", string(fastCtx.Response.Header.ContentType()))
+	assert.EqualValues(t, "no-cache", string(fastCtx.Response.Header.Peek(fasthttp.HeaderCacheControl)))
+	assert.EqualValues(t, "id: 1\nevent: count\ndata: 1\n\n: two is next\n\nid: 2\nevent: count\ndata: 2\n\n",
+		string(fastCtx.Response.Body()))
+}
+
+func TestSSEHeartbeat(t *testing.T) {
+	g := New().SetSSEHeartbeat(5 * time.Millisecond)
+	g.Router().Get("/events", &SSEHandler{Stream: func(w *SSEWriter) {
+		time.Sleep(30 * time.Millisecond)
+		w.Close()
+	}})
+
+	fastCtx := serveTest(g, MethodGet, "/events")
+	assert.True(t, strings.HasPrefix(string(fastCtx.Response.Body()), ": ping\n\n"))
+}
+
+func TestSSEDisconnect(t *testing.T) {
+	sent := make(chan error, 1)
+	h := &SSEHandler{writer: make(chan *SSEWriter, 1), Stream: func(w *SSEWriter) {
+		for i := 0; ; i++ {
+			if err := w.Send(SSEEvent{Data: i}); err != nil {
+				sent <- err
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}}
+
+	g := New()
+	g.Router().Get("/events", h)
+
+	fastCtx := serveTest(g, MethodGet, "/events")
+	w := <-h.writer
+
+	buf := make([]byte, 8)
+	_, err := io.ReadFull(fastCtx.Response.BodyStream(), buf)
+	assert.Nil(t, err)
+	assert.EqualValues(t, "data: 0\n", string(buf))
+
+	// the client goes away: the stream ends and the request context is canceled
+	assert.Nil(t, fastCtx.Response.CloseBodyStream())
+	waitDone(t, w.Done())
+	assert.True(t, errors.Is(<-sent, SSEClosedError))
+	assert.True(t, errors.Is(w.Send(SSEEvent{Data: "late"}), SSEClosedError))
+}
+
+func TestSSEHandlerError(t *testing.T) {
+	h := &SSEHandler{writer: make(chan *SSEWriter, 1), Err: ServiceUnavailable("")}
+
+	g := New()
+	g.Router().Get("/events", h)
+
+	// the error response replaces the stream, the request context is canceled as usual
+	fastCtx := serveTest(g, MethodGet, "/events")
+	w := <-h.writer
+	waitDone(t, w.Done())
+	assert.EqualValues(t, fasthttp.StatusServiceUnavailable, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "Service Unavailable", string(fastCtx.Response.Body()))
+}
+
+func TestSSEHub(t *testing.T) {
+	hub := NewSSEHub(2)
+
+	g := New().SetSSEHeartbeat(-1)
+	g.Router().Get("/events", &SSEHandler{Hub: hub})
+
+	for _, data := range []string{"a", "b", "c"} {
+		assert.Nil(t, hub.Publish(SSEEvent{Data: data}))
+	}
+
+	// "1" is out of the history, so nothing is resumed
+	first := serveTest(g, MethodGet, "/events", withHeader("Last-Event-ID", "1"))
+	resumed := serveTest(g, MethodGet, "/events", withHeader("Last-Event-ID", "2"))
+	assert.EqualValues(t, 2, hub.Len())
+
+	source := make(chan SSEEvent)
+	go func() {
+		source <- SSEEvent{ID: "last", Event: "end", Data: "d"}
+		close(source)
+	}()
+	assert.Nil(t, hub.Run(context.Background(), source))
+	hub.Close()
+
+	assert.EqualValues(t, "id: last\nevent: end\ndata: d\n\n", string(first.Response.Body()))
+	assert.EqualValues(t, "id: 3\ndata: c\n\nid: last\nevent: end\ndata: d\n\n", string(resumed.Response.Body()))
+	assert.EqualValues(t, 0, hub.Len())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	assert.True(t, errors.Is(hub.Run(ctx, make(chan SSEEvent)), context.Canceled))
+}
@@ -2,7 +2,6 @@ package gorouter
 
 import (
 	"os"
-	"regexp"
 	"strings"
 
 	"github.com/iostrovok/gorouter/static"
@@ -24,6 +23,10 @@ func (h *StaticFileHandler) Name() string {
 }
 
 func (h *StaticFileHandler) Run(ctx *Context) error {
+	if bareStaticPrefix(ctx) {
+		return NotFound("")
+	}
+
 	old := string(ctx.fastCtx.Path())
 	defer func() {
 		ctx.fastCtx.URI().SetPath(old)
@@ -38,6 +41,12 @@ func (h *StaticFileHandler) Run(ctx *Context) error {
 	return h.httpHandler.ServeHTTP(ctx.fastCtx)
 }
 
+// bareStaticPrefix checks the request is for the prefix itself: "/static" of "/static/*filepath".
+// The catch-all segment matches it with the empty value, but only paths under the prefix are static files.
+func bareStaticPrefix(ctx *Context) bool {
+	return ctx.urlIDs != nil && len(ctx.urlIDs.Peek("filepath")) == 0 && !strings.HasSuffix(string(ctx.fastCtx.Path()), "/")
+}
+
 // StaticFile works with simple static files
 func (server *Server) StaticFile(filePath, urlPath string, sets ...*HandlerSet) error {
 	h := &StaticFileHandler{
@@ -48,11 +57,6 @@ func (server *Server) StaticFile(filePath, urlPath string, sets ...*HandlerSet)
 
 	server.Debugf("static_file sets prefix: from '%s' to '%s'", urlPath, filePath)
 
-	pathReg, err := regexp.Compile("^" + urlPath + "/.*")
-	if err != nil {
-		return err
-	}
-
 	var set *HandlerSet
 	if len(sets) > 0 {
 		set = sets[0]
@@ -60,6 +64,6 @@ func (server *Server) StaticFile(filePath, urlPath string, sets ...*HandlerSet)
 		set = Set("static for '" + urlPath + "'")
 	}
 
-	server.AddReg(MethodGetHead, pathReg, h, set)
+	server.Add(MethodGetHead, strings.TrimSuffix(urlPath, "/")+"/*filepath", h, set)
 	return nil
 }
@@ -4,7 +4,6 @@ import (
 	"embed"
 	"io/fs"
 	"path"
-	"regexp"
 	"strings"
 
 	"github.com/iostrovok/gorouter/static"
@@ -23,6 +22,10 @@ func (h *StaticHandler) Init(_ *Context) error {
 }
 
 func (h *StaticHandler) Run(ctx *Context) error {
+	if bareStaticPrefix(ctx) {
+		return NotFound("")
+	}
+
 	old := string(ctx.fastCtx.Path())
 	defer func() {
 		ctx.fastCtx.URI().SetPath(old)
@@ -55,18 +58,13 @@ func (server *Server) StaticFileFS(filePath, urlPath string, emfs embed.FS, sets
 
 	server.Debugf("static_file sets prefix: from '%s' to '%s'", urlPath, filePath)
 
-	pathReg, err := regexp.Compile("^" + urlPath + "/.*")
-	if err != nil {
-		return err
-	}
-
 	var set *HandlerSet
 	if len(sets) > 0 {
 		set = sets[0]
 	} else {
 		set = Set("static for '" + urlPath + "'")
 	}
-	server.AddReg(MethodGetHead, pathReg, h, set)
+	server.Add(MethodGetHead, strings.TrimSuffix(urlPath, "/")+"/*filepath", h, set)
 
 	return nil
 }
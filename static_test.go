@@ -0,0 +1,28 @@
+package gorouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+func TestStaticFilePrefix(t *testing.T) {
+	g := New()
+	assert.Nil(t, g.StaticFile("./static", "/files"))
+
+	fastCtx := serveTest(g, MethodGet, "/files/sniff.go")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.Contains(t, string(fastCtx.Response.Body()), "package static")
+
+	// only paths under the prefix are served
+	for _, uri := range []string{"/files", "/files?filepath=sniff.go", "/filesniff.go"} {
+		fastCtx = serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusNotFound, fastCtx.Response.StatusCode(), uri)
+	}
+
+	// the static route is the tree route, it doesn't shadow other routes
+	g.Get("/files/readme", newWH("readme"))
+	fastCtx = serveTest(g, MethodGet, "/files/readme")
+	assert.EqualValues(t, "readme", string(fastCtx.Response.Body()))
+}
@@ -0,0 +1,23 @@
+package gorouter
+
+import (
+	"context"
+	"errors"
+
+	"github.com/valyala/fasthttp"
+)
+
+// timeoutError returns 503 for the pipeline stopped by the deadline and 504 if the handler
+// returns context.DeadlineExceeded, other errors are kept.
+func (ctx *Context) timeoutError(err error) error {
+	ctx.logger.Add("timed_out", true)
+
+	switch {
+	case err == nil:
+		return NewHTTPError(fasthttp.StatusServiceUnavailable, "request timeout").Wrap(context.DeadlineExceeded)
+	case errors.Is(err, context.DeadlineExceeded):
+		return NewHTTPError(fasthttp.StatusGatewayTimeout, "").Wrap(err)
+	}
+
+	return err
+}
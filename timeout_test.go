@@ -0,0 +1,97 @@
+package gorouter
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+type SleepHandler struct {
+	RunHandler
+
+	Sleep     time.Duration
+	ReturnErr bool // returns the error of the request context like the upstream call
+}
+
+func (h *SleepHandler) Run(ctx *Context) error {
+	select {
+	case <-time.After(h.Sleep):
+	case <-ctx.Ctx().Done():
+		if h.ReturnErr {
+			return ctx.Ctx().Err()
+		}
+	}
+
+	return nil
+}
+
+type TimeoutLastHandler struct {
+	Fields map[string]any
+}
+
+func (h *TimeoutLastHandler) Name() string {
+	return "TimeoutLastHandler"
+}
+
+func (h *TimeoutLastHandler) Run(ctx *Context, err error) error {
+	h.Fields = ctx.Logger().Fields
+	return err
+}
+
+func TestServerTimeout(t *testing.T) {
+	last := &TimeoutLastHandler{}
+
+	g := New().SetTimeout(20 * time.Millisecond)
+	g.Router().
+		Last(last).
+		Get("/slow", &SleepHandler{Sleep: time.Second}).
+		Get("/upstream", &SleepHandler{Sleep: time.Second, ReturnErr: true}).
+		Get("/fast", newWH("fast"))
+
+	g.Router().Timeout(time.Second).
+		After(newWH(" after")).
+		Get("/long", &SleepHandler{Sleep: 50 * time.Millisecond}).
+		Timeout(10*time.Millisecond).
+		Get("/short", &SleepHandler{Sleep: time.Second})
+
+	g.Add(MethodGet, "/set", &SleepHandler{Sleep: time.Second}, Set("").Timeout(10*time.Millisecond).After(newWH("after")))
+
+	start := time.Now()
+	fastCtx := serveTest(g, MethodGet, "/slow")
+	assert.Less(t, time.Since(start), 500*time.Millisecond)
+	assert.EqualValues(t, fasthttp.StatusServiceUnavailable, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, "request timeout", string(fastCtx.Response.Body()))
+	assert.EqualValues(t, true, last.Fields["timed_out"])
+	assert.EqualValues(t, "20ms", last.Fields["timeout"])
+
+	fastCtx = serveTest(g, MethodGet, "/upstream")
+	assert.EqualValues(t, fasthttp.StatusGatewayTimeout, fastCtx.Response.StatusCode())
+
+	fastCtx = serveTest(g, MethodGet, "/fast")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.Nil(t, last.Fields["timed_out"])
+
+	// the router timeout overrides the server default
+	fastCtx = serveTest(g, MethodGet, "/long")
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	assert.EqualValues(t, " after", string(fastCtx.Response.Body()))
+
+	// the pipeline is stopped: no "after" handlers
+	for _, uri := range []string{"/short", "/set"} {
+		fastCtx = serveTest(g, MethodGet, uri)
+		assert.EqualValues(t, fasthttp.StatusServiceUnavailable, fastCtx.Response.StatusCode(), uri)
+		assert.EqualValues(t, "request timeout", string(fastCtx.Response.Body()), uri)
+	}
+}
+
+func TestServerTimeoutProblem(t *testing.T) {
+	g := New().SetTimeout(10 * time.Millisecond).Last(NewProblemHandler())
+	g.Router().Get("/slow", &SleepHandler{Sleep: time.Second})
+
+	fastCtx := serveTest(g, MethodGet, "/slow")
+	assert.EqualValues(t, fasthttp.StatusServiceUnavailable, fastCtx.Response.StatusCode())
+	assert.JSONEq(t, `{"type":"about:blank","title":"Service Unavailable","status":503,"detail":"request timeout","instance":"/slow"}`,
+		string(fastCtx.Response.Body()))
+}
@@ -1,6 +1,9 @@
 package gorouter
 
-import "sync"
+import (
+	"sync"
+	"time"
+)
 
 type HandlerSet struct {
 	sync.RWMutex
@@ -11,6 +14,9 @@ type HandlerSet struct {
 	after   []IHandler   // handlers after main handler
 	last    ILastHandler // always last handler with error from handlers as parameter
 	handler IRunHandler  // main handler
+
+	timeout time.Duration // deadline of the request, the server default is used if it's zero
+	uploads *UploadLimits // limits of multipart uploads, the server default is used if it's nil
 }
 
 func Set(id string) *HandlerSet {
@@ -38,7 +44,7 @@ func (set *HandlerSet) Run(context *Context) error {
 			return err
 		}
 
-		if context.Stopped() {
+		if context.Stopped() || context.TimedOut() {
 			return nil
 		}
 	}
@@ -49,7 +55,7 @@ func (set *HandlerSet) Run(context *Context) error {
 			return err
 		}
 
-		if context.Stopped() {
+		if context.Stopped() || context.TimedOut() {
 			return nil
 		}
 	}
@@ -61,7 +67,7 @@ func (set *HandlerSet) Run(context *Context) error {
 			return err
 		}
 
-		if context.Stopped() {
+		if context.Stopped() || context.TimedOut() {
 			return nil
 		}
 	}
@@ -80,6 +86,23 @@ func (set *HandlerSet) RunLast(ctx *Context, err error) error {
 	return set.last.Run(ctx, err)
 }
 
+// runLast runs the last handler of the set or the default one if the set has no last handler.
+func (set *HandlerSet) runLast(ctx *Context, err error, def ILastHandler) error {
+	set.RLock()
+	last := set.last
+	set.RUnlock()
+
+	if last == nil {
+		last = def
+	}
+
+	if last == nil {
+		return err
+	}
+
+	return last.Run(ctx, err)
+}
+
 func (set *HandlerSet) Last(handler ILastHandler) *HandlerSet {
 	set.Lock()
 	defer set.Unlock()
@@ -104,6 +127,48 @@ func (set *HandlerSet) After(handler ...IHandler) *HandlerSet {
 	return set
 }
 
+// Timeout sets up the deadline of the request context.
+func (set *HandlerSet) Timeout(timeout time.Duration) *HandlerSet {
+	set.Lock()
+	defer set.Unlock()
+
+	set.timeout = timeout
+	return set
+}
+
+func (set *HandlerSet) GetTimeout() time.Duration {
+	set.RLock()
+	defer set.RUnlock()
+
+	return set.timeout
+}
+
+// UploadLimits sets up the limits of multipart uploads of the request, see Context.FormFile.
+func (set *HandlerSet) UploadLimits(limits UploadLimits) *HandlerSet {
+	set.Lock()
+	defer set.Unlock()
+
+	set.uploads = &limits
+	return set
+}
+
+// uploadLimits sets up the limits if they are not nil.
+func (set *HandlerSet) uploadLimits(limits *UploadLimits) *HandlerSet {
+	if limits != nil {
+		return set.UploadLimits(*limits)
+	}
+
+	return set
+}
+
+// GetUploadLimits returns the limits of multipart uploads or nil if the set has no own limits.
+func (set *HandlerSet) GetUploadLimits() *UploadLimits {
+	set.RLock()
+	defer set.RUnlock()
+
+	return set.uploads
+}
+
 func (set *HandlerSet) Use(handler IRunHandler) *HandlerSet {
 	set.Lock()
 	defer set.Unlock()
@@ -112,6 +177,31 @@ func (set *HandlerSet) Use(handler IRunHandler) *HandlerSet {
 	return set
 }
 
+// mount adds all handlers of the module set to the set: its "before" and "after" handlers go inside
+// the handlers of the set and its "last" handler replaces the last handler of the set.
+func (set *HandlerSet) mount(module *HandlerSet) *HandlerSet {
+	module.RLock()
+	defer module.RUnlock()
+
+	set.Lock()
+	defer set.Unlock()
+
+	set.before = append(set.before, module.before...)
+	set.after = append(set.after, module.after...)
+	set.handler = module.handler
+	if module.last != nil {
+		set.last = module.last
+	}
+	if module.timeout > 0 {
+		set.timeout = module.timeout
+	}
+	if module.uploads != nil {
+		set.uploads = module.uploads
+	}
+
+	return set
+}
+
 func (set *HandlerSet) Clone() *HandlerSet {
 	set.Lock()
 	defer set.Unlock()
@@ -122,5 +212,56 @@ func (set *HandlerSet) Clone() *HandlerSet {
 		after:   set.after[:],
 		handler: set.handler,
 		last:    set.last,
+		timeout: set.timeout,
+		uploads: set.uploads,
+	}
+}
+
+// BeforeNames returns names of "before" handlers, for debug goals only.
+func (set *HandlerSet) BeforeNames() []string {
+	set.RLock()
+	defer set.RUnlock()
+
+	return handlerNames(set.before)
+}
+
+// AfterNames returns names of "after" handlers, for debug goals only.
+func (set *HandlerSet) AfterNames() []string {
+	set.RLock()
+	defer set.RUnlock()
+
+	return handlerNames(set.after)
+}
+
+// HandlerName returns name of the main handler, for debug goals only.
+func (set *HandlerSet) HandlerName() string {
+	set.RLock()
+	defer set.RUnlock()
+
+	if set.handler == nil {
+		return ""
 	}
+
+	return set.handler.Name()
+}
+
+// LastName returns name of the last handler, for debug goals only.
+func (set *HandlerSet) LastName() string {
+	set.RLock()
+	defer set.RUnlock()
+
+	if set.last == nil {
+		return ""
+	}
+
+	return set.last.Name()
+}
+
+func handlerNames(handlers []IHandler) []string {
+	out := make([]string, len(handlers))
+	for i := range handlers {
+		out[i] = handlers[i].Name()
+	}
+
+	return out
 }
@@ -1,24 +1,46 @@
 package gorouter
 
 import (
+	"sort"
 	"strings"
 	"sync"
+	"unicode"
 
 	"github.com/valyala/fasthttp"
 )
 
+// Tree is a compressed radix tree of routes.
+//
+// Static paths share common prefixes, ":name" segments are params and the last "*name" segment is
+// a catch-all param which takes the rest of the path. Params may have constraints: ":id<int>".
+// The lookup prefers static nodes to params, params with constraints to params without them and params
+// to catch-all params, and it goes back to the next candidate when a branch doesn't match.
+//
+// Find takes no locks and does no allocations, so all routes must be added before the tree is used for lookups.
 type Tree struct {
+	lock sync.Mutex
+
 	Top *Node
 }
 
 type Node struct {
-	sync.RWMutex
-
+	// static children, indices keeps the first bytes of their paths in the same order
 	Children []*Node
+	indices  []byte
+
+	// param children are checked after static ones, children with constraints go first
+	params []*Node
+	// catch-all child is checked last
+	wildcard *Node
 
-	Path     string
-	UrlId    string
+	Path     string // static prefix of the node, it's empty for params
+	UrlId    string // name of param or catch-all param
+	CatchAll bool   // "*name" segment, takes the rest of the path
 	Handlers map[Method]*HandlerSet
+
+	TrailingSlash bool // the route is registered with the trailing slash: "/about/"
+
+	constraint *paramConstraint // optional constraint of the param
 }
 
 func newTree() *Tree {
@@ -31,23 +53,12 @@ func newTree() *Tree {
 	}
 }
 
-func nextNodes(path, urlId string, children []*Node) []*Node {
-	if urlId != "" {
-		return children[:]
-	}
-
-	for j := range children {
-		if children[j].Path == path {
-			return []*Node{children[j]}
-		}
-	}
-
-	return []*Node{}
-}
-
 type TreeResult struct {
-	HandlerSet *HandlerSet
-	Find       bool
+	HandlerSet    *HandlerSet
+	Find          bool
+	TrailingSlash bool // the found route is registered with the trailing slash
+
+	regexp bool // the route is found in the regexp tree
 }
 
 func splitPath(path string) []string {
@@ -62,6 +73,12 @@ func splitPath(path string) []string {
 	return out
 }
 
+// trimPath is the same as splitPath, but it doesn't split the path and doesn't allocate memory.
+// The result has no leading and trailing slashes: "/a/b/" => "a/b".
+func trimPath(path string) string {
+	return strings.TrimRightFunc(strings.Trim(strings.TrimSpace(path), "/"), unicode.IsSpace)
+}
+
 func addMethod(method Method, handlers map[Method]*HandlerSet, set *HandlerSet) map[Method]*HandlerSet {
 	switch method {
 	case MethodGetPost:
@@ -77,167 +94,416 @@ func addMethod(method Method, handlers map[Method]*HandlerSet, set *HandlerSet)
 	return handlers
 }
 
-type nextNode struct {
-	node *Node
-	path []string
-	args *fasthttp.Args
+type urlParam struct {
+	key, value string
+}
+
+// findState keeps url params of the current branch, they are copied to args for the found node only.
+type findState struct {
+	params []urlParam
+
+	// methods collects methods of all matched nodes instead of the lookup of the single one
+	methods map[Method]bool
+
+	// fold is the case-insensitive lookup, pieces keep the matched path in the registered casing
+	fold   bool
+	pieces []string
+}
+
+var findStatePool = sync.Pool{
+	New: func() any {
+		return &findState{params: make([]urlParam, 0, 8)}
+	},
+}
+
+func acquireFindState() *findState {
+	return findStatePool.Get().(*findState)
+}
+
+func releaseFindState(st *findState) {
+	st.params = st.params[:0]
+	st.methods = nil
+	st.fold = false
+	st.pieces = st.pieces[:0]
+	findStatePool.Put(st)
+}
+
+func (st *findState) push(key, value string) {
+	st.params = append(st.params, urlParam{key: key, value: value})
+}
+
+func (st *findState) pop() {
+	st.params = st.params[:len(st.params)-1]
+}
+
+func (st *findState) piece(path string) {
+	if st.fold {
+		st.pieces = append(st.pieces, path)
+	}
+}
+
+func (st *findState) unpiece() {
+	if st.fold {
+		st.pieces = st.pieces[:len(st.pieces)-1]
+	}
+}
+
+// hasPrefix is strings.HasPrefix which ignores the case in the fold mode.
+func (st *findState) hasPrefix(s, prefix string) bool {
+	if !st.fold {
+		return strings.HasPrefix(s, prefix)
+	}
+
+	return len(s) >= len(prefix) && strings.EqualFold(s[:len(prefix)], prefix)
+}
+
+func lowerByte(b byte) byte {
+	if 'A' <= b && b <= 'Z' {
+		return b + 'a' - 'A'
+	}
+
+	return b
 }
 
 func (t *Tree) Find(method Method, path string, inOutArgs *fasthttp.Args) TreeResult {
 	inOutArgs.Reset()
-	pathsIn := splitPath(path)
-
-	if len(pathsIn) == 1 {
-		t.Top.RLock()
-		defer t.Top.RUnlock()
 
-		// "/" case
-		if handlers, find := t.Top.Handlers[method]; find && handlers != nil {
-			return TreeResult{
-				HandlerSet: handlers,
-				Find:       find,
-			}
-		}
+	st := acquireFindState()
+	defer releaseFindState(st)
 
+	node := t.Top.lookup(trimPath(path), method, st)
+	if node == nil {
 		return TreeResult{}
 	}
 
-	nodes := []*nextNode{{
-		node: t.Top,
-		path: pathsIn,
-		args: &fasthttp.Args{},
-	}}
+	for i := range st.params {
+		inOutArgs.Add(st.params[i].key, st.params[i].value)
+	}
+
+	return TreeResult{
+		HandlerSet:    node.Handlers[method],
+		Find:          true,
+		TrailingSlash: node.TrailingSlash,
+	}
+}
+
+// FindFold is the case-insensitive Find, it also returns the path of the found route in the registered casing.
+func (t *Tree) FindFold(method Method, path string, inOutArgs *fasthttp.Args) (TreeResult, string) {
+	inOutArgs.Reset()
+
+	st := acquireFindState()
+	defer releaseFindState(st)
+
+	st.fold = true
+	node := t.Top.lookup(trimPath(path), method, st)
+	if node == nil {
+		return TreeResult{}, ""
+	}
+
+	for i := range st.params {
+		inOutArgs.Add(st.params[i].key, st.params[i].value)
+	}
 
-	allArgs := []*fasthttp.Args{nodes[0].args}
-	defer func() {
-		for i := range allArgs {
-			fasthttp.ReleaseArgs(allArgs[i])
+	out := "/" + strings.Join(st.pieces, "")
+	if node.TrailingSlash && out != "/" {
+		out += "/"
+	}
+
+	return TreeResult{
+		HandlerSet:    node.Handlers[method],
+		Find:          true,
+		TrailingSlash: node.TrailingSlash,
+	}, out
+}
+
+// accept checks the node has handlers for the method.
+func (node *Node) accept(method Method, st *findState) bool {
+	if st.methods == nil {
+		return node.Handlers[method] != nil
+	}
+
+	for m, h := range node.Handlers {
+		if h != nil {
+			st.methods[m] = true
 		}
-	}()
+	}
 
-	nodeId := 0
-	for {
-		if nodeId >= len(nodes) {
-			return TreeResult{}
+	// collect methods from all nodes
+	return false
+}
+
+// lookup finds the node for the rest of the path below the node.
+func (node *Node) lookup(path string, method Method, st *findState) *Node {
+	if path == "" && node.accept(method, st) {
+		return node
+	}
+
+	// static children have the highest priority, only one of them may match (or more in the fold mode)
+	first := byte('/')
+	if path != "" {
+		first = path[0]
+	}
+
+	for i := range node.indices {
+		if node.indices[i] != first && (!st.fold || lowerByte(node.indices[i]) != lowerByte(first)) {
+			continue
 		}
 
-		node := nodes[nodeId].node
-		paths := nodes[nodeId].path
-		urlArgs := nodes[nodeId].args
-		nodeId++
+		child := node.Children[i]
+		if st.hasPrefix(path, child.Path) {
+			st.piece(child.Path)
+			if found := child.lookup(path[len(child.Path):], method, st); found != nil {
+				return found
+			}
+			st.unpiece()
+		} else if child.wildcard != nil && len(child.Path) == len(path)+1 && st.hasPrefix(child.Path, path) {
+			// "/files" matches "/files/*path" with empty path
+			st.piece(child.Path[:len(path)])
+			if found := child.lookup("", method, st); found != nil {
+				return found
+			}
+			st.unpiece()
+		}
 
-		if node.UrlId != "" {
-			urlArgs.Add(node.UrlId, paths[0])
-		} else if node.Path != paths[0] {
-			return TreeResult{}
+		if !st.fold {
+			break
 		}
+	}
 
-		if len(paths) == 1 {
-			node.RLock()
-			handlers, find := node.Handlers[method]
-			node.RUnlock()
+	// params take the whole segment
+	if path != "" && len(node.params) > 0 {
+		end := strings.IndexByte(path, '/')
+		if end < 0 {
+			end = len(path)
+		}
 
-			if find && handlers != nil {
-				// copy inOutArgs
-				urlArgs.VisitAll(func(key, value []byte) {
-					inOutArgs.AddBytesKV(key, value)
-				})
+		if end > 0 {
+			for _, child := range node.params {
+				if !child.constraint.match(path[:end]) {
+					continue
+				}
 
-				return TreeResult{
-					HandlerSet: handlers,
-					Find:       true,
+				st.push(child.UrlId, path[:end])
+				st.piece(path[:end])
+				if found := child.lookup(path[end:], method, st); found != nil {
+					return found
 				}
+				st.unpiece()
+				st.pop()
 			}
-
-			return TreeResult{}
 		}
+	}
 
-		for i := range node.Children {
-			if node.Children[i].Path == paths[1] || node.Children[i].UrlId != "" {
-				allArgs = cloneArgs(urlArgs, allArgs)
-				nodes = append(nodes, &nextNode{
-					node: node.Children[i],
-					path: paths[1:],
-					args: allArgs[len(allArgs)-1],
-				})
-			}
+	// catch-all param takes the rest of the path
+	if node.wildcard != nil {
+		st.push(node.wildcard.UrlId, path)
+		st.piece(path)
+		if node.wildcard.accept(method, st) {
+			return node.wildcard
 		}
+		st.unpiece()
+		st.pop()
 	}
+
+	return nil
 }
 
-func cloneArgs(urlArgs *fasthttp.Args, allArgs []*fasthttp.Args) []*fasthttp.Args {
-	newArgs := fasthttp.AcquireArgs()
-	urlArgs.VisitAll(func(key, value []byte) {
-		newArgs.AddBytesKV(key, value)
-	})
+func (t *Tree) Add(method Method, path string, set *HandlerSet) {
+	t.lock.Lock()
+	defer t.lock.Unlock()
 
-	allArgs = append(allArgs, newArgs)
-	return allArgs
+	pattern := trimPath(path)
+	node := t.Top.insert(pattern, path)
+	node.Handlers = addMethod(method, node.Handlers, set)
+	node.TrailingSlash = pattern != "" && strings.HasSuffix(strings.TrimSpace(path), "/")
 }
 
-func (t *Tree) Add(method Method, path string, set *HandlerSet) {
-	t.Top.Lock()
-	defer t.Top.Unlock()
+// insert creates all nodes for the pattern below the node and returns the last one.
+func (node *Node) insert(pattern, fullPath string) *Node {
+	for pattern != "" {
+		switch pattern[0] {
+		case ':':
+			end := paramEnd(pattern)
+			name, constraint, err := splitParam(pattern[1:end])
+			if err != nil {
+				panic(err.Error() + " in '" + fullPath + "'")
+			}
 
-	paths := splitPath(path)
-	last := len(paths) - 1
-	if last == 0 {
-		t.Top.Handlers = addMethod(method, t.Top.Handlers, set)
-		return
+			node = node.paramChild(name, constraint, fullPath)
+			pattern = pattern[end:]
+		case '*':
+			if strings.IndexByte(pattern, '/') >= 0 {
+				panic("catch-all segment '" + pattern + "' must be the last one in '" + fullPath + "'")
+			}
+
+			node = node.wildcardChild(pattern[1:], fullPath)
+			pattern = ""
+		default:
+			end := staticEnd(pattern)
+			node = node.staticChild(pattern[:end])
+			pattern = pattern[end:]
+		}
 	}
 
-	t._add(t.Top, method, paths[1:], set)
+	return node
 }
 
-func getUrlID(p string) (string, string) {
-	if strings.HasPrefix(p, ":") {
-		return "", strings.TrimLeft(p, ":")
+// staticEnd returns the length of the static prefix of the pattern, it includes the slash before a param.
+func staticEnd(pattern string) int {
+	for i := 0; i < len(pattern)-1; i++ {
+		if pattern[i] == '/' && (pattern[i+1] == ':' || pattern[i+1] == '*') {
+			return i + 1
+		}
 	}
-	return p, ""
+
+	return len(pattern)
 }
 
-func (t *Tree) _add(node *Node, method Method, paths []string, set *HandlerSet) {
-	next := nextNodes(paths[0], node.UrlId, node.Children)
+func commonPrefix(a, b string) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
 
-	// final point, need to set up HandlerSet
-	if len(paths) == 1 {
-		if len(next) == 0 {
-			path, urlId := getUrlID(paths[0])
-			node.Children = append(node.Children, &Node{
-				UrlId:    urlId,
-				Path:     path,
+	return i
+}
+
+// staticChild returns the node for the static path below the node, nodes are split by the common prefixes.
+func (node *Node) staticChild(path string) *Node {
+	for {
+		i := 0
+		for i < len(node.indices) && node.indices[i] != path[0] {
+			i++
+		}
+
+		if i == len(node.indices) {
+			child := &Node{
 				Children: []*Node{},
-				Handlers: addMethod(method, map[Method]*HandlerSet{}, set),
-			})
-		} else {
-			for i := range next {
-				next[i].Handlers = addMethod(method, next[i].Handlers, set)
+				Path:     path,
+				Handlers: map[Method]*HandlerSet{},
 			}
+			node.Children = append(node.Children, child)
+			node.indices = append(node.indices, path[0])
+
+			return child
+		}
+
+		child := node.Children[i]
+		l := commonPrefix(path, child.Path)
+		if l < len(child.Path) {
+			child.split(l)
+		}
+
+		if l == len(path) {
+			return child
 		}
 
-		return
+		node = child
+		path = path[l:]
 	}
+}
 
-	if len(next) == 0 {
-		for _, p := range paths {
-			path, urlId := getUrlID(p)
-			n := &Node{
-				UrlId:    urlId,
-				Path:     path,
-				Children: []*Node{},
-				Handlers: map[Method]*HandlerSet{},
-			}
-			node.Children = append(node.Children, n)
-			node = n
+// split moves the tail of the node path with all children and handlers to the new child.
+func (node *Node) split(l int) {
+	tail := &Node{
+		Children: node.Children,
+		indices:  node.indices,
+		params:   node.params,
+		wildcard: node.wildcard,
+		Path:     node.Path[l:],
+		Handlers: node.Handlers,
+
+		TrailingSlash: node.TrailingSlash,
+	}
+
+	node.Children = []*Node{tail}
+	node.indices = []byte{tail.Path[0]}
+	node.params = nil
+	node.wildcard = nil
+	node.Path = node.Path[:l]
+	node.Handlers = map[Method]*HandlerSet{}
+	node.TrailingSlash = false
+}
+
+func (node *Node) paramChild(name, constraint, fullPath string) *Node {
+	if name == "" {
+		panic("empty param name in '" + fullPath + "'")
+	}
+
+	for _, child := range node.params {
+		if child.UrlId == name && child.constraint.expression() == constraint {
+			return child
 		}
+	}
+
+	child := &Node{
+		Children: []*Node{},
+		UrlId:    name,
+		Handlers: map[Method]*HandlerSet{},
+	}
+
+	if constraint == "" {
+		node.params = append(node.params, child)
+		return child
+	}
+
+	c, err := parseConstraint(constraint)
+	if err != nil {
+		panic(err.Error() + " in '" + fullPath + "'")
+	}
+	child.constraint = c
+
+	// params with constraints are checked before params without them, in registration order
+	pos := 0
+	for pos < len(node.params) && node.params[pos].constraint != nil {
+		pos++
+	}
+
+	node.params = append(node.params, nil)
+	copy(node.params[pos+1:], node.params[pos:])
+	node.params[pos] = child
+
+	return child
+}
+
+func (node *Node) wildcardChild(name, fullPath string) *Node {
+	if name == "" {
+		panic("empty catch-all param name in '" + fullPath + "'")
+	}
 
-		node.Handlers = addMethod(method, node.Handlers, set)
-		return
+	// the conflicting name is reported by the route check, the first name is kept
+	if node.wildcard == nil {
+		node.wildcard = &Node{
+			Children: []*Node{},
+			UrlId:    name,
+			CatchAll: true,
+			Handlers: map[Method]*HandlerSet{},
+		}
 	}
 
-	for i := range next {
-		t._add(next[i], method, paths[1:], set)
+	return node.wildcard
+}
+
+// Methods returns the sorted list of methods registered for all nodes matching the path.
+func (t *Tree) Methods(path string) []Method {
+	st := acquireFindState()
+	defer releaseFindState(st)
+
+	st.methods = map[Method]bool{}
+	t.Top.lookup(trimPath(path), "", st)
+
+	return sortMethods(st.methods)
+}
+
+func sortMethods(found map[Method]bool) []Method {
+	out := make([]Method, 0, len(found))
+	for m := range found {
+		out = append(out, m)
 	}
+
+	sort.Slice(out, func(i, j int) bool { return out[i] < out[j] })
+
+	return out
 }
 
 func (t *Tree) String() string {
@@ -245,7 +511,11 @@ func (t *Tree) String() string {
 }
 
 func (node *Node) print(tab string) string {
-	out := tab + "Path: " + node.Path + ", UrlId: " + node.UrlId + " => "
+	out := tab + "Path: " + node.Path + ", UrlId: " + node.UrlId + node.constraint.String()
+	if node.CatchAll {
+		out += ", CatchAll"
+	}
+	out += " => "
 	for m, h := range node.Handlers {
 		out += "/" + string(m) + "[" + h.ID + "]"
 	}
@@ -256,5 +526,13 @@ func (node *Node) print(tab string) string {
 		out += node.Children[i].print(tab)
 	}
 
+	for i := range node.params {
+		out += node.params[i].print(tab)
+	}
+
+	if node.wildcard != nil {
+		out += node.wildcard.print(tab)
+	}
+
 	return out
 }
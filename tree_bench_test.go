@@ -0,0 +1,106 @@
+package gorouter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+var benchPaths = []string{
+	"/",
+	"/my-ads",
+	"/my-ads/upload",
+	"/my-ads/list",
+	"/my-ads/take/:id",
+	"/my-ads/take/:id/json",
+	"/my-ads/take/:id/thumbnails",
+	"/my-ads/:id/property/update",
+	"/my-ads/:id/condition/:condition/add",
+	"/my-ads/:id/condition/:condition/del",
+	"/my-ads/:id/video_search/count",
+	"/my-ads/attached_video/:id",
+	"/advertiser/profile",
+	"/advertiser/profile/data",
+	"/advertiser/profile/budget/add",
+	"/static/*filepath",
+}
+
+func benchTree() *Tree {
+	tr := newTree()
+	for _, path := range benchPaths {
+		tr.Add(MethodGetPost, path, Set(path))
+	}
+
+	return tr
+}
+
+func benchFind(b *testing.B, path string) {
+	tr := benchTree()
+	args := &fasthttp.Args{}
+
+	if res := tr.Find(MethodGet, path, args); !res.Find {
+		b.Fatalf("path '%s' not found", path)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		tr.Find(MethodGet, path, args)
+	}
+}
+
+func BenchmarkTreeFindRoot(b *testing.B) {
+	benchFind(b, "/")
+}
+
+func BenchmarkTreeFindStatic(b *testing.B) {
+	benchFind(b, "/advertiser/profile/budget/add")
+}
+
+func BenchmarkTreeFindParam(b *testing.B) {
+	benchFind(b, "/my-ads/15/condition/new/del")
+}
+
+func BenchmarkTreeFindBacktrack(b *testing.B) {
+	// "take" static node goes first and fails, so the lookup goes back to ":id" param
+	benchFind(b, "/my-ads/take/property/update")
+}
+
+func BenchmarkTreeFindCatchAll(b *testing.B) {
+	benchFind(b, "/static/css/main/style.css")
+}
+
+func BenchmarkTreeFindParallel(b *testing.B) {
+	tr := benchTree()
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		args := &fasthttp.Args{}
+		for pb.Next() {
+			tr.Find(MethodGet, "/my-ads/15/condition/new/del", args)
+		}
+	})
+}
+
+func TestTreeFindAllocs(t *testing.T) {
+	tr := benchTree()
+	args := &fasthttp.Args{}
+
+	paths := []string{
+		"/",
+		"/advertiser/profile/budget/add",
+		"/my-ads/15/condition/new/del",
+		"/my-ads/take/property/update",
+		"/static/css/main/style.css",
+		"/not/found",
+	}
+
+	for _, path := range paths {
+		allocs := testing.AllocsPerRun(100, func() {
+			tr.Find(MethodGet, path, args)
+		})
+		assert.EqualValues(t, 0, allocs, "expected no allocations for '"+path+"'")
+	}
+}
@@ -431,3 +431,132 @@ func TestTreeUrlID_4(t *testing.T) {
 	assert.NotNil(t, res, "expected not nil for '/'")
 	assert.EqualValues(t, false, res.Find, "expected false for '/'")
 }
+
+func TestTreeCatchAll(t *testing.T) {
+	tr := newTree()
+	assert.NotNil(t, tr)
+
+	tr.Add(MethodGet, "/files/*path", Set("1"))
+	tr.Add(MethodGet, "/files/special/file", Set("2"))
+	tr.Add(MethodGet, "/user/:id/*rest", Set("3"))
+
+	t.Logf("\n---------\nTestTreeCatchAll:\n%s\n---------\n", tr.String())
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	res := tr.Find(MethodGet, "/files/a/b/c.txt", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/files/a/b/c.txt'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/files/a/b/c.txt'")
+	assert.EqualValues(t, "a/b/c.txt", string(args.Peek("path")), "expected res.UrlIDs for '/files/a/b/c.txt'")
+
+	res = tr.Find(MethodGet, "/files/special/file", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/files/special/file'")
+	assert.EqualValues(t, "2", res.HandlerSet.ID, "expected 2 for '/files/special/file'")
+
+	res = tr.Find(MethodGet, "/files/special", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/files/special'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/files/special'")
+	assert.EqualValues(t, "special", string(args.Peek("path")), "expected res.UrlIDs for '/files/special'")
+
+	res = tr.Find(MethodGet, "/files", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/files'")
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/files'")
+	assert.EqualValues(t, "", string(args.Peek("path")), "expected res.UrlIDs for '/files'")
+
+	res = tr.Find(MethodGet, "/user/15/a/b", args)
+	assert.EqualValues(t, true, res.Find, "expected true for '/user/15/a/b'")
+	assert.EqualValues(t, "3", res.HandlerSet.ID, "expected 3 for '/user/15/a/b'")
+	assert.EqualValues(t, "15", string(args.Peek("id")), "expected res.UrlIDs for '/user/15/a/b'")
+	assert.EqualValues(t, "a/b", string(args.Peek("rest")), "expected res.UrlIDs for '/user/15/a/b'")
+
+	res = tr.Find(MethodPost, "/files/a/b/c.txt", args)
+	assert.EqualValues(t, false, res.Find, "expected false for POST '/files/a/b/c.txt'")
+
+	assert.Panics(t, func() { tr.Add(MethodGet, "/bad/*path/tail", Set("4")) })
+}
+
+func TestTreePriority(t *testing.T) {
+	tr := newTree()
+	assert.NotNil(t, tr)
+
+	tr.Add(MethodGet, "/user/:id", Set("1"))
+	tr.Add(MethodGet, "/user/new", Set("2"))
+	tr.Add(MethodGet, "/user/new/:tab", Set("3"))
+	tr.Add(MethodGet, "/user/:id/profile", Set("4"))
+	tr.Add(MethodPost, "/user/:id", Set("5"))
+	tr.Add(MethodGet, "/user/*rest", Set("6"))
+
+	t.Logf("\n---------\nTestTreePriority:\n%s\n---------\n", tr.String())
+
+	args := &fasthttp.Args{}
+	defer args.Reset()
+
+	res := tr.Find(MethodGet, "/user/new", args)
+	assert.EqualValues(t, "2", res.HandlerSet.ID, "static node goes before param for '/user/new'")
+	assert.EqualValues(t, 0, args.Len(), "expected no res.UrlIDs for '/user/new'")
+
+	res = tr.Find(MethodGet, "/user/newbie", args)
+	assert.EqualValues(t, "1", res.HandlerSet.ID, "expected 1 for '/user/newbie'")
+	assert.EqualValues(t, "newbie", string(args.Peek("id")), "expected res.UrlIDs for '/user/newbie'")
+
+	// "new" static branch has no "profile" child, so the lookup goes back to ":id"
+	res = tr.Find(MethodGet, "/user/new/profile", args)
+	assert.EqualValues(t, "3", res.HandlerSet.ID, "expected 3 for '/user/new/profile'")
+	assert.EqualValues(t, "profile", string(args.Peek("tab")), "expected res.UrlIDs for '/user/new/profile'")
+
+	res = tr.Find(MethodGet, "/user/15/profile", args)
+	assert.EqualValues(t, "4", res.HandlerSet.ID, "expected 4 for '/user/15/profile'")
+	assert.EqualValues(t, "15", string(args.Peek("id")), "expected res.UrlIDs for '/user/15/profile'")
+
+	// "new" static node has no POST handler
+	res = tr.Find(MethodPost, "/user/new", args)
+	assert.EqualValues(t, "5", res.HandlerSet.ID, "expected 5 for POST '/user/new'")
+	assert.EqualValues(t, "new", string(args.Peek("id")), "expected res.UrlIDs for POST '/user/new'")
+
+	res = tr.Find(MethodGet, "/user/15/settings/mail", args)
+	assert.EqualValues(t, "6", res.HandlerSet.ID, "expected 6 for '/user/15/settings/mail'")
+	assert.EqualValues(t, "15/settings/mail", string(args.Peek("rest")), "expected res.UrlIDs for '/user/15/settings/mail'")
+	assert.EqualValues(t, false, args.Has("id"), "expected no 'id' for '/user/15/settings/mail'")
+
+	assert.EqualValues(t, []Method{MethodGet, MethodPost}, tr.Methods("/user/new"))
+	assert.EqualValues(t, []Method{MethodGet}, tr.Methods("/user/new/profile"))
+	assert.EqualValues(t, []Method{}, tr.Methods("/unknown"))
+}
+
+func TestTreeConstraints(t *testing.T) {
+	tr := newTree()
+	tr.Add(MethodGet, "/item/:name", Set("name"))
+	tr.Add(MethodGet, "/item/:id<int>", Set("int"))
+	tr.Add(MethodGet, "/item/:id<uuid>", Set("uuid"))
+	tr.Add(MethodGet, "/item/:id<int>/tab/:tab<enum(info,files)>", Set("tab"))
+	tr.Add(MethodGet, "/post/:slug<[a-z-]+>", Set("slug"))
+	tr.Add(MethodGet, "/path/:p<[a-z]+/[a-z]+>", Set("slash"))
+
+	t.Logf("\n---------\nTestTreeConstraints:\n%s\n---------\n", tr.String())
+
+	data := map[string][2]string{
+		"/item/15":  {"int", "15"},
+		"/item/-15": {"int", "-15"},
+		"/item/abc": {"name", "abc"},
+		"/item/1e5": {"name", "1e5"},
+		"/item/6ba7b810-9dad-11d1-80b4-00c04fd430c8": {"uuid", "6ba7b810-9dad-11d1-80b4-00c04fd430c8"},
+		"/item/15/tab/files":                         {"tab", "15"},
+		"/post/hello-world":                          {"slug", ""},
+	}
+
+	args := &fasthttp.Args{}
+	for path, expected := range data {
+		res := tr.Find(MethodGet, path, args)
+		assert.True(t, res.Find, path)
+		assert.EqualValues(t, expected[0], res.HandlerSet.ID, path)
+		if expected[1] != "" {
+			// only one of params is set
+			assert.EqualValues(t, expected[1], string(args.Peek("id"))+string(args.Peek("name")), path)
+		}
+	}
+
+	for _, path := range []string{"/item/15/tab/other", "/item/abc/tab/info", "/post/Hello", "/path/a/b"} {
+		assert.False(t, tr.Find(MethodGet, path, args).Find, path)
+	}
+}
@@ -0,0 +1,301 @@
+package gorouter
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"strings"
+
+	"github.com/valyala/fasthttp"
+
+	"github.com/iostrovok/gorouter/static"
+)
+
+var (
+	UploadNotMultipartError = errors.New("not multipart form")
+	UploadTooManyFilesError = errors.New("too many files")
+	UploadPartTooLargeError = errors.New("part is too large")
+	UploadTypeError         = errors.New("file type is not allowed")
+	UploadMissingFileError  = errors.New("missing file")
+	UploadInvalidFormError  = errors.New("invalid multipart form")
+)
+
+const (
+	uploadSniffLen    = 512 // static.DetectContentType reads at most 512 bytes
+	uploadTempPattern = "gorouter-upload-*"
+)
+
+// UploadLimits restricts multipart uploads, zero values mean no limit.
+type UploadLimits struct {
+	MaxFiles    int   // max number of files in the request
+	MaxPartSize int64 // max size of the single part (file or value)
+
+	// AllowedTypes are MIME types of files detected by content sniffing (static.DetectContentType),
+	// "image/*" allows all images.
+	AllowedTypes []string
+}
+
+func (server *Server) UploadLimits() UploadLimits {
+	return server.uploads
+}
+
+// SetUploadLimits sets up the default limits of multipart uploads for routes without their own limits.
+func (server *Server) SetUploadLimits(limits UploadLimits) *Server {
+	server.uploads = limits
+	return server
+}
+
+// allowed checks the sniffed type of the file.
+func (limits *UploadLimits) allowed(contentType string) bool {
+	if len(limits.AllowedTypes) == 0 {
+		return true
+	}
+
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	mediaType = strings.TrimSpace(mediaType)
+	for _, allowed := range limits.AllowedTypes {
+		if allowed == mediaType || (strings.HasSuffix(allowed, "/*") && strings.HasPrefix(mediaType, allowed[:len(allowed)-1])) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (limits *UploadLimits) tooManyFiles(files int) error {
+	if limits.MaxFiles > 0 && files > limits.MaxFiles {
+		return NewHTTPError(fasthttp.StatusRequestEntityTooLarge, UploadTooManyFilesError.Error()).
+			Wrap(fmt.Errorf("%w: the limit is %d", UploadTooManyFilesError, limits.MaxFiles))
+	}
+
+	return nil
+}
+
+func (limits *UploadLimits) partTooLarge(name string) error {
+	return NewHTTPError(fasthttp.StatusRequestEntityTooLarge, "part '"+name+"' is too large").
+		Wrap(fmt.Errorf("%w: '%s', the limit is %d bytes", UploadPartTooLargeError, name, limits.MaxPartSize))
+}
+
+func (limits *UploadLimits) typeError(name, contentType string) error {
+	return NewHTTPError(fasthttp.StatusUnsupportedMediaType, "type of file '"+name+"' is not allowed").
+		Wrap(fmt.Errorf("%w: '%s' is '%s'", UploadTypeError, name, contentType))
+}
+
+// FormFile returns the first file of the multipart form field, the form is parsed by fasthttp.
+// The upload limits of the route are checked, the errors are HTTPError: 413 for too many files or too large file,
+// 415 for not allowed type or not multipart request and 400 for missing file.
+func (ctx *Context) FormFile(name string) (*multipart.FileHeader, error) {
+	form, err := ctx.fastCtx.MultipartForm()
+	if errors.Is(err, fasthttp.ErrNoMultipartForm) {
+		return nil, NewHTTPError(fasthttp.StatusUnsupportedMediaType, "multipart form is expected").Wrap(UploadNotMultipartError)
+	} else if err != nil {
+		return nil, BadRequest(UploadInvalidFormError.Error()).Wrap(fmt.Errorf("%w: %s", UploadInvalidFormError, err.Error()))
+	}
+
+	files := 0
+	for _, list := range form.File {
+		files += len(list)
+	}
+	if err := ctx.uploads.tooManyFiles(files); err != nil {
+		return nil, err
+	}
+
+	if len(form.File[name]) == 0 {
+		return nil, BadRequest("file '" + name + "' is required").Wrap(fmt.Errorf("%w: '%s'", UploadMissingFileError, name))
+	}
+
+	header := form.File[name][0]
+	if ctx.uploads.MaxPartSize > 0 && header.Size > ctx.uploads.MaxPartSize {
+		return nil, ctx.uploads.partTooLarge(name)
+	}
+
+	if len(ctx.uploads.AllowedTypes) > 0 {
+		contentType, err := sniffFile(header)
+		if err != nil {
+			return nil, err
+		}
+		if !ctx.uploads.allowed(contentType) {
+			return nil, ctx.uploads.typeError(name, contentType)
+		}
+	}
+
+	return header, nil
+}
+
+func sniffFile(header *multipart.FileHeader) (string, error) {
+	file, err := header.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buf := make([]byte, uploadSniffLen)
+	n, err := io.ReadFull(file, buf)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return static.DetectContentType(buf[:n]), nil
+}
+
+// SaveUploadedFile saves the file of the multipart form to dst.
+func (ctx *Context) SaveUploadedFile(header *multipart.FileHeader, dst string) error {
+	return fasthttp.SaveMultipartFile(header, dst)
+}
+
+// removeTempFiles removes temp files of the request, it's called when the request finishes.
+func (ctx *Context) removeTempFiles() {
+	for _, name := range ctx.tempFiles {
+		if err := os.Remove(name); err != nil && !errors.Is(err, os.ErrNotExist) {
+			ctx.Logger().Error(err).Errorf("temp file '%s' is not removed", name)
+		}
+	}
+
+	ctx.tempFiles = nil
+}
+
+// UploadReader reads parts of the multipart form one by one, the body is never buffered as the whole
+// if fasthttp.Server.StreamRequestBody is set up (see Server.SetServer).
+type UploadReader struct {
+	ctx    *Context
+	reader *multipart.Reader
+	files  int
+}
+
+// UploadPart is the part of the multipart form. Reads of the part respect the upload limits of the route.
+type UploadPart struct {
+	*multipart.Part
+
+	// ContentType is the sniffed type of the file or empty string for form values.
+	ContentType string
+
+	ctx    *Context
+	reader io.Reader
+}
+
+// MultipartReader returns the streaming reader of the multipart form.
+func (ctx *Context) MultipartReader() (*UploadReader, error) {
+	boundary := ctx.fastCtx.Request.Header.MultipartFormBoundary()
+	if len(boundary) == 0 {
+		return nil, NewHTTPError(fasthttp.StatusUnsupportedMediaType, "multipart form is expected").Wrap(UploadNotMultipartError)
+	}
+
+	body := ctx.fastCtx.RequestBodyStream()
+	if body == nil {
+		body = bytes.NewReader(ctx.fastCtx.Request.Body())
+	}
+
+	return &UploadReader{ctx: ctx, reader: multipart.NewReader(body, string(boundary))}, nil
+}
+
+// NextPart returns the next part of the form or io.EOF if there are no more parts.
+// The limits on the number of files and allowed types are checked here, the size of the part is checked on reading.
+func (r *UploadReader) NextPart() (*UploadPart, error) {
+	part, err := r.reader.NextPart()
+	if errors.Is(err, io.EOF) {
+		return nil, io.EOF
+	} else if err != nil {
+		return nil, BadRequest(UploadInvalidFormError.Error()).Wrap(fmt.Errorf("%w: %s", UploadInvalidFormError, err.Error()))
+	}
+
+	limits := &r.ctx.uploads
+	out := &UploadPart{Part: part, ctx: r.ctx, reader: part}
+
+	if part.FileName() != "" {
+		r.files++
+		if err := limits.tooManyFiles(r.files); err != nil {
+			return nil, err
+		}
+
+		buffered := bufio.NewReaderSize(part, uploadSniffLen)
+		head, err := buffered.Peek(uploadSniffLen)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, BadRequest(UploadInvalidFormError.Error()).Wrap(fmt.Errorf("%w: %s", UploadInvalidFormError, err.Error()))
+		}
+
+		out.ContentType = static.DetectContentType(head)
+		if !limits.allowed(out.ContentType) {
+			return nil, limits.typeError(part.FormName(), out.ContentType)
+		}
+		out.reader = buffered
+	}
+
+	if limits.MaxPartSize > 0 {
+		out.reader = &limitedPart{reader: out.reader, left: limits.MaxPartSize, err: limits.partTooLarge(part.FormName())}
+	}
+
+	return out, nil
+}
+
+// IsFile checks the part is the file.
+func (p *UploadPart) IsFile() bool {
+	return p.FileName() != ""
+}
+
+func (p *UploadPart) Read(b []byte) (int, error) {
+	return p.reader.Read(b)
+}
+
+// SaveTo writes the part to the file dst, the file is removed if the part can't be read.
+func (p *UploadPart) SaveTo(dst string) (int64, error) {
+	file, err := os.Create(dst)
+	if err != nil {
+		return 0, err
+	}
+
+	n, err := io.Copy(file, p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		_ = os.Remove(dst)
+	}
+
+	return n, err
+}
+
+// SaveTemp writes the part to the temp file, the file is removed when the request finishes.
+func (p *UploadPart) SaveTemp() (string, error) {
+	file, err := os.CreateTemp("", uploadTempPattern)
+	if err != nil {
+		return "", err
+	}
+	p.ctx.tempFiles = append(p.ctx.tempFiles, file.Name())
+
+	_, err = io.Copy(file, p)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+
+	return file.Name(), err
+}
+
+// limitedPart returns the error if the part is larger than the limit.
+type limitedPart struct {
+	reader io.Reader
+	left   int64
+	err    error
+}
+
+func (l *limitedPart) Read(b []byte) (int, error) {
+	if l.left < 0 {
+		return 0, l.err
+	}
+
+	// one byte more to find out the part exceeds the limit
+	if int64(len(b)) > l.left+1 {
+		b = b[:l.left+1]
+	}
+
+	n, err := l.reader.Read(b)
+	l.left -= int64(n)
+	if l.left < 0 {
+		return n + int(l.left), l.err
+	}
+
+	return n, err
+}
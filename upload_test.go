@@ -0,0 +1,184 @@
+package gorouter
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/valyala/fasthttp"
+)
+
+const pngHead = "\x89PNG\r\n\x1a\n"
+
+type FormFileHandler struct {
+	RunHandler
+
+	Dir string
+	Err error
+}
+
+func (h *FormFileHandler) Run(ctx *Context) error {
+	header, err := ctx.FormFile("file")
+	if h.Err = err; err != nil {
+		return err
+	}
+
+	return ctx.SaveUploadedFile(header, filepath.Join(h.Dir, header.Filename))
+}
+
+type StreamUploadHandler struct {
+	RunHandler
+
+	Parts []string // form name, sniffed type and size of parts
+	Temp  []string
+	Err   error
+}
+
+func (h *StreamUploadHandler) Run(ctx *Context) error {
+	h.Parts, h.Temp, h.Err = nil, nil, nil
+
+	reader, err := ctx.MultipartReader()
+	if h.Err = err; err != nil {
+		return err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if errors.Is(err, io.EOF) {
+			return nil
+		}
+		if h.Err = err; err != nil {
+			return err
+		}
+
+		if !part.IsFile() {
+			value, err := io.ReadAll(part)
+			if h.Err = err; err != nil {
+				return err
+			}
+			h.Parts = append(h.Parts, part.FormName()+"="+string(value))
+			continue
+		}
+
+		name, err := part.SaveTemp()
+		h.Temp = append(h.Temp, name)
+		if h.Err = err; err != nil {
+			return err
+		}
+
+		body, _ := os.ReadFile(name)
+		h.Parts = append(h.Parts, part.FormName()+":"+part.ContentType+":"+string(body))
+	}
+}
+
+// withMultipart sets up the multipart form, the pairs are the field name and its content, the file names start with "@".
+func withMultipart(pairs ...string) func(req *fasthttp.Request) {
+	body := &bytes.Buffer{}
+	mw := multipart.NewWriter(body)
+	for i := 0; i+1 < len(pairs); i += 2 {
+		if name, ok := strings.CutPrefix(pairs[i], "@"); ok {
+			w, _ := mw.CreateFormFile(name, name+".bin")
+			_, _ = w.Write([]byte(pairs[i+1]))
+		} else {
+			_ = mw.WriteField(pairs[i], pairs[i+1])
+		}
+	}
+	_ = mw.Close()
+
+	return func(req *fasthttp.Request) {
+		req.Header.SetContentType(mw.FormDataContentType())
+		req.SetBody(body.Bytes())
+	}
+}
+
+func TestFormFile(t *testing.T) {
+	h := &FormFileHandler{Dir: t.TempDir()}
+
+	g := New().SetUploadLimits(UploadLimits{MaxFiles: 2, MaxPartSize: 16})
+	g.Router().Post("/any", h)
+	g.Router().UploadLimits(UploadLimits{AllowedTypes: []string{"image/*"}}).Post("/image", h)
+
+	fastCtx := serveTest(g, MethodPost, "/any", withMultipart("title", "x", "@file", "hello"))
+	assert.Nil(t, h.Err)
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+	saved, err := os.ReadFile(filepath.Join(h.Dir, "file.bin"))
+	assert.Nil(t, err)
+	assert.EqualValues(t, "hello", string(saved))
+
+	fastCtx = serveTest(g, MethodPost, "/image", withMultipart("@file", pngHead+"image data"))
+	assert.Nil(t, h.Err)
+	assert.EqualValues(t, fasthttp.StatusOK, fastCtx.Response.StatusCode())
+
+	data := []struct {
+		uri   string
+		pairs []string
+		code  int
+		err   error
+	}{
+		{"/any", []string{"@file", "the file is too large"}, fasthttp.StatusRequestEntityTooLarge, UploadPartTooLargeError},
+		{"/any", []string{"@file", "a", "@b", "b", "@c", "c"}, fasthttp.StatusRequestEntityTooLarge, UploadTooManyFilesError},
+		{"/any", []string{"@other", "a"}, fasthttp.StatusBadRequest, UploadMissingFileError},
+		{"/image", []string{"@file", "hello"}, fasthttp.StatusUnsupportedMediaType, UploadTypeError},
+	}
+
+	for _, d := range data {
+		fastCtx = serveTest(g, MethodPost, d.uri, withMultipart(d.pairs...))
+		assert.True(t, errors.Is(h.Err, d.err), d.pairs)
+		assert.EqualValues(t, d.code, fastCtx.Response.StatusCode(), d.pairs)
+	}
+
+	fastCtx = serveTest(g, MethodPost, "/any")
+	assert.True(t, errors.Is(h.Err, UploadNotMultipartError))
+	assert.EqualValues(t, fasthttp.StatusUnsupportedMediaType, fastCtx.Response.StatusCode())
+}
+
+func TestMultipartReader(t *testing.T) {
+	h := &StreamUploadHandler{}
+
+	g := New()
+	g.Router().Post("/upload", h)
+	g.Router().UploadLimits(UploadLimits{MaxFiles: 1, MaxPartSize: 16, AllowedTypes: []string{"image/png"}}).Post("/limited", h)
+
+	serveTest(g, MethodPost, "/upload", withMultipart("title", "x", "@file", "hello", "@image", pngHead))
+	assert.Nil(t, h.Err)
+	assert.EqualValues(t, []string{"title=x", "file:text/plain; charset=utf-8:hello", "image:image/png:" + pngHead}, h.Parts)
+
+	// temp files are removed when the request finishes
+	assert.Len(t, h.Temp, 2)
+	for _, name := range h.Temp {
+		_, err := os.Stat(name)
+		assert.True(t, errors.Is(err, os.ErrNotExist), name)
+	}
+
+	data := []struct {
+		pairs []string
+		code  int
+		err   error
+	}{
+		{[]string{"@file", pngHead + "more than 16 bytes"}, fasthttp.StatusRequestEntityTooLarge, UploadPartTooLargeError},
+		{[]string{"title", "more than 16 bytes"}, fasthttp.StatusRequestEntityTooLarge, UploadPartTooLargeError},
+		{[]string{"@file", pngHead, "@other", pngHead}, fasthttp.StatusRequestEntityTooLarge, UploadTooManyFilesError},
+		{[]string{"@file", "hello"}, fasthttp.StatusUnsupportedMediaType, UploadTypeError},
+	}
+
+	for _, d := range data {
+		fastCtx := serveTest(g, MethodPost, "/limited", withMultipart(d.pairs...))
+		assert.True(t, errors.Is(h.Err, d.err), d.pairs)
+		assert.EqualValues(t, d.code, fastCtx.Response.StatusCode(), d.pairs)
+	}
+
+	for _, name := range h.Temp {
+		_, err := os.Stat(name)
+		assert.True(t, errors.Is(err, os.ErrNotExist), name)
+	}
+
+	fastCtx := serveTest(g, MethodPost, "/upload")
+	assert.True(t, errors.Is(h.Err, UploadNotMultipartError))
+	assert.EqualValues(t, fasthttp.StatusUnsupportedMediaType, fastCtx.Response.StatusCode())
+}